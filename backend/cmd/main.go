@@ -1,33 +1,79 @@
 package main
 
 import (
+	"arshaka-backend/internal/buildinfo"
+	"arshaka-backend/internal/config"
 	httpHandler "arshaka-backend/internal/delivery/http"
+	"arshaka-backend/internal/metrics"
 	"arshaka-backend/internal/repository/mysql"
+	"arshaka-backend/internal/scheduler"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
+	"arshaka-backend/pkg/cache"
 	"arshaka-backend/pkg/database"
-	"log"
+	"arshaka-backend/pkg/ratelimit"
+	"arshaka-backend/pkg/tlsreload"
+	"arshaka-backend/pkg/tracing"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // kegiatan timezones must resolve in minimal container images
 
-	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 )
 
 func main() {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
+	// Configuration from config.yaml, .env and the environment
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	slog.SetDefault(cfg.Log.Logger())
+
+	// Tracing of requests, usecases and SQL statements
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		Version:     buildinfo.Get().Commit,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("failed to set up tracing", err)
 	}
 
 	// Database connection
-	dbConfig := database.GetConfigFromEnv()
-	db, err := database.NewMySQLConnection(dbConfig)
+	db, err := database.NewMySQLConnection(cfg.Database.Connection())
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("failed to connect to database", err)
 	}
 	defer db.Close()
 
+	// Prometheus metrics, including the connection pool
+	appMetrics := metrics.New(db)
+
+	// Public API responses, dropped by the usecases whenever an admin changes their data
+	responseCache := cache.New(cfg.Cache.MaxEntries)
+	appMetrics.WatchCache(responseCache)
+
+	// Rate limit buckets, in Redis when several instances must share them
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "redis" {
+		redisClient, err := ratelimit.NewRedisClient(context.Background(), cfg.RateLimit.RedisURL)
+		if err != nil {
+			fatal("failed to connect to redis", err)
+		}
+		defer redisClient.Close()
+		limitStore = ratelimit.NewRedisStore(redisClient, "arshaka:ratelimit:")
+	}
+
 	// Initialize repositories
 	adminRepo := mysql.NewAdminRepository(db)
 	bannerRepo := mysql.NewBannerRepository(db)
@@ -36,112 +82,207 @@ func main() {
 	strukturRepo := mysql.NewStrukturRepository(db)
 	pembinaRepo := mysql.NewPembinaRepository(db)
 	qrcodeRepo := mysql.NewQRCodeRepository(db)
+	categoryRepo := mysql.NewCategoryRepository(db)
+	tagRepo := mysql.NewTagRepository(db)
+	uploadedFileRepo := mysql.NewUploadedFileRepository(db)
+	revisionRepo := mysql.NewRevisionRepository(db)
+	transactor := mysql.NewTransactor(db)
+	schemaRepo := mysql.NewSchemaRepository(db)
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(adminRepo)
-	bannerUsecase := usecase.NewBannerUsecase(bannerRepo)
-	kegiatanUsecase := usecase.NewKegiatanUsecase(kegiatanRepo)
-	kegiatanPhotoUsecase := usecase.NewKegiatanPhotoUsecase(kegiatanPhotoRepo)
-	strukturUsecase := usecase.NewStrukturUsecase(strukturRepo)
-	pembinaUsecase := usecase.NewPembinaUsecase(pembinaRepo)
-	qrcodeUsecase := usecase.NewQRCodeUsecase(qrcodeRepo)
+	authUsecase := usecase.NewAuthUsecase(adminRepo, cfg.Auth.JWTSecret, cfg.Auth.SessionTimeout)
+	bannerUsecase := usecase.NewBannerUsecase(bannerRepo, responseCache)
+	kegiatanUsecase := usecase.NewKegiatanUsecase(kegiatanRepo, tagRepo, uploadedFileRepo, revisionRepo, responseCache)
+	kegiatanPhotoUsecase := usecase.NewKegiatanPhotoUsecase(kegiatanPhotoRepo, responseCache)
+	strukturUsecase := usecase.NewStrukturUsecase(strukturRepo, revisionRepo, transactor, responseCache)
+	pembinaUsecase := usecase.NewPembinaUsecase(pembinaRepo, responseCache)
+	qrcodeUsecase := usecase.NewQRCodeUsecase(qrcodeRepo, responseCache)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, responseCache)
+	tagUsecase := usecase.NewTagUsecase(tagRepo, responseCache)
+	trashUsecase := usecase.NewTrashUsecase(kegiatanRepo, kegiatanPhotoRepo, bannerRepo, strukturRepo, pembinaRepo, qrcodeRepo,
+		uploadedFileRepo, revisionRepo, cfg.Upload.Path, cfg.Trash.Retention(), responseCache)
+	healthUsecase := usecase.NewHealthUsecase(schemaRepo, cfg.Upload.Path)
+
+	// Give kegiatan created before slugs existed a slug of their own
+	if generated, err := kegiatanUsecase.GenerateMissingSlugs(context.Background()); err != nil {
+		slog.Warn("failed to generate kegiatan slugs", "error", err)
+	} else if generated > 0 {
+		slog.Info("generated kegiatan slugs", "count", generated)
+	}
+
+	// Background jobs
+	jobs := scheduler.New()
+	jobs.Observe(appMetrics.ObserveJob)
+	jobs.Add("publish-scheduled-kegiatan", time.Minute, func(ctx context.Context) error {
+		published, err := kegiatanUsecase.PublishScheduled(ctx)
+		if published > 0 {
+			slog.Info("published scheduled kegiatan", "count", published)
+		}
+		return err
+	})
+	jobs.Add("invalidate-kegiatan-state", time.Minute, func(ctx context.Context) error {
+		changed, err := kegiatanUsecase.InvalidateStateChanges(ctx)
+		if changed > 0 {
+			slog.Info("kegiatan changed state", "count", changed)
+		}
+		return err
+	})
+	jobs.Add("purge-trash", time.Hour, func(ctx context.Context) error {
+		purged, err := trashUsecase.PurgeExpired(ctx)
+		if purged > 0 {
+			slog.Info("purged trash", "count", purged)
+		}
+		return err
+	})
+	jobs.Start(context.Background())
 
 	// Initialize handlers
-	authHandler := httpHandler.NewAuthHandler(authUsecase)
-	bannerHandler := httpHandler.NewBannerHandler(bannerUsecase)
-	kegiatanHandler := httpHandler.NewKegiatanHandler(kegiatanUsecase)
-	kegiatanPhotoHandler := httpHandler.NewKegiatanPhotoHandler(kegiatanPhotoUsecase)
-	strukturHandler := httpHandler.NewStrukturHandler(strukturUsecase)
-	pembinaHandler := httpHandler.NewPembinaHandler(pembinaUsecase)
-	qrcodeHandler := httpHandler.NewQRCodeHandler(qrcodeUsecase)
-	uploadHandler := httpHandler.NewUploadHandler()
-
-	// Setup routes
-	router := mux.NewRouter()
-
-	// API routes
-	api := router.PathPrefix("/api").Subrouter()
-
-	// Auth routes (public)
-	api.HandleFunc("/admin/login", authHandler.Login).Methods("POST")
-
-	// Public routes
-	api.HandleFunc("/banners", bannerHandler.GetAll).Methods("GET")
-	api.HandleFunc("/kegiatan", kegiatanHandler.GetAll).Methods("GET")
-	api.HandleFunc("/kegiatan/{id}", kegiatanHandler.GetByID).Methods("GET")
-	api.HandleFunc("/kegiatan/{kegiatan_id}/photos", kegiatanPhotoHandler.GetByKegiatanID).Methods("GET")
-	api.HandleFunc("/struktur", strukturHandler.GetAll).Methods("GET")
-	api.HandleFunc("/pembina", pembinaHandler.GetAll).Methods("GET")
-	api.HandleFunc("/qrcode/enabled", qrcodeHandler.GetEnabled).Methods("GET")
-
-	// Protected admin routes
-	adminAPI := api.PathPrefix("/admin").Subrouter()
-	adminAPI.Use(httpHandler.JWTMiddleware)
-
-	// Banner admin routes
-	adminAPI.HandleFunc("/banners", bannerHandler.GetAll).Methods("GET")
-	adminAPI.HandleFunc("/banners/{id}", bannerHandler.GetByID).Methods("GET")
-	adminAPI.HandleFunc("/banners", bannerHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/banners/{id}", bannerHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/banners/{id}", bannerHandler.Delete).Methods("DELETE")
-
-	// Kegiatan admin routes
-	adminAPI.HandleFunc("/kegiatan", kegiatanHandler.GetAll).Methods("GET")
-	adminAPI.HandleFunc("/kegiatan/{id}", kegiatanHandler.GetByID).Methods("GET")
-	adminAPI.HandleFunc("/kegiatan", kegiatanHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/kegiatan/{id}", kegiatanHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/kegiatan/{id}", kegiatanHandler.Delete).Methods("DELETE")
-
-	// Struktur admin routes
-	adminAPI.HandleFunc("/struktur", strukturHandler.GetAll).Methods("GET")
-	adminAPI.HandleFunc("/struktur/{id}", strukturHandler.GetByID).Methods("GET")
-	adminAPI.HandleFunc("/struktur", strukturHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/struktur/{id}", strukturHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/struktur/{id}", strukturHandler.Delete).Methods("DELETE")
-
-	// Pembina admin routes
-	adminAPI.HandleFunc("/pembina", pembinaHandler.GetAll).Methods("GET")
-	adminAPI.HandleFunc("/pembina/{id}", pembinaHandler.GetByID).Methods("GET")
-	adminAPI.HandleFunc("/pembina", pembinaHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/pembina/{id}", pembinaHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/pembina/{id}", pembinaHandler.Delete).Methods("DELETE")
-
-	// QR Code admin routes
-	adminAPI.HandleFunc("/qrcode", qrcodeHandler.GetAll).Methods("GET")
-	adminAPI.HandleFunc("/qrcode/{id}", qrcodeHandler.GetByID).Methods("GET")
-	adminAPI.HandleFunc("/qrcode", qrcodeHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/qrcode/{id}", qrcodeHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/qrcode/{id}", qrcodeHandler.Delete).Methods("DELETE")
-	adminAPI.HandleFunc("/qrcode/{id}/toggle", qrcodeHandler.ToggleEnable).Methods("PUT")
-
-	// Kegiatan Photos admin routes
-	adminAPI.HandleFunc("/kegiatan/{kegiatan_id}/photos", kegiatanPhotoHandler.Create).Methods("POST")
-	adminAPI.HandleFunc("/photos/{photo_id}", kegiatanPhotoHandler.Update).Methods("PUT")
-	adminAPI.HandleFunc("/photos/{photo_id}", kegiatanPhotoHandler.Delete).Methods("DELETE")
-	adminAPI.HandleFunc("/photos/sort-order", kegiatanPhotoHandler.UpdateSortOrder).Methods("PUT")
-
-	// Upload routes (protected)
-	adminAPI.HandleFunc("/upload/image", uploadHandler.UploadImage).Methods("POST")
-	adminAPI.HandleFunc("/upload/images", uploadHandler.UploadMultipleImages).Methods("POST")
-
-	// Static file serving for uploads
-	router.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads/"))))
+	validator := validation.New(uploadedFileRepo, cfg.Upload.Path)
+	authHandler := httpHandler.NewAuthHandler(authUsecase, validator, appMetrics)
+	bannerHandler := httpHandler.NewBannerHandler(bannerUsecase, validator)
+	kegiatanHandler := httpHandler.NewKegiatanHandler(kegiatanUsecase, validator)
+	kegiatanPhotoHandler := httpHandler.NewKegiatanPhotoHandler(kegiatanPhotoUsecase, kegiatanUsecase, validator)
+	strukturHandler := httpHandler.NewStrukturHandler(strukturUsecase, validator)
+	pembinaHandler := httpHandler.NewPembinaHandler(pembinaUsecase, validator)
+	qrcodeHandler := httpHandler.NewQRCodeHandler(qrcodeUsecase, validator)
+	categoryHandler := httpHandler.NewCategoryHandler(categoryUsecase, validator)
+	tagHandler := httpHandler.NewTagHandler(tagUsecase, validator)
+	trashHandler := httpHandler.NewTrashHandler(trashUsecase)
+	uploadHandler := httpHandler.NewUploadHandler(uploadedFileRepo, cfg.Upload.Path, cfg.Upload.MaxSize, cfg.Upload.MaxBatchSize, appMetrics)
+	siteURL := cfg.Server.SiteURL
+	calendarHandler := httpHandler.NewCalendarHandler(kegiatanUsecase, siteURL)
+	feedHandler := httpHandler.NewFeedHandler(kegiatanUsecase, siteURL)
+	previewHandler := httpHandler.NewPreviewHandler(kegiatanUsecase, siteURL)
+	openAPIHandler := httpHandler.NewOpenAPIHandler()
+	healthHandler := httpHandler.NewHealthHandler(healthUsecase)
+	metricsHandler := httpHandler.NewMetricsHandler(appMetrics, cfg.Metrics.Token)
+	cacheHandler := httpHandler.NewCacheHandler(responseCache, cfg.Cache.TTL, cfg.Cache.MaxAge)
+	rateLimiter := httpHandler.NewRateLimiter(limitStore, cfg.RateLimit.Proxies())
+
+	router := httpHandler.NewRouter(httpHandler.Routes{
+		Health:        healthHandler,
+		Metrics:       metricsHandler,
+		Feed:          feedHandler,
+		Preview:       previewHandler,
+		OpenAPI:       openAPIHandler,
+		Auth:          authHandler,
+		Banner:        bannerHandler,
+		Kegiatan:      kegiatanHandler,
+		KegiatanPhoto: kegiatanPhotoHandler,
+		Calendar:      calendarHandler,
+		Struktur:      strukturHandler,
+		Pembina:       pembinaHandler,
+		QRCode:        qrcodeHandler,
+		Category:      categoryHandler,
+		Tag:           tagHandler,
+		Trash:         trashHandler,
+		Upload:        uploadHandler,
+		Cache:         cacheHandler,
+		RateLimiter:   rateLimiter,
+		PublicLimit:   cfg.RateLimit.Public(),
+		LoginLimit:    cfg.RateLimit.Login(),
+		AdminLimit:    cfg.RateLimit.Admin(),
+		JWTSecret:     cfg.Auth.JWTSecret,
+		UploadsDir:    cfg.Upload.Path,
+	})
 
 	// CORS configuration
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3001"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
+		AllowedOrigins: cfg.CORS.AllowedOrigins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{"ETag", "Last-Modified", "X-Cache", "X-Request-ID",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 	})
 
-	handler := c.Handler(router)
+	securityHeaders := httpHandler.SecurityHeaders(cfg.Security.Headers(cfg.Server.TLS.Enabled()))
+	handler := httpHandler.Trace(httpHandler.RequestLogger(securityHeaders(metricsHandler.Instrument(httpHandler.Compress(c.Handler(httpHandler.LimitBody(cfg.Server.MaxBodyBytes)(router)))))))
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(srv, cfg.Server.TLS)
+	}()
+
+	select {
+	case err := <-serveErr:
+		jobsCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		jobs.Stop(jobsCtx)
+		cancel()
+		fatal("server failed", err)
+	case <-ctx.Done():
+	}
+
+	// Let in-flight requests and running jobs finish before exiting
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("requests still running at shutdown were cut off", "timeout", cfg.Server.ShutdownTimeout.String(), "error", err)
+	}
+	jobs.Stop(shutdownCtx)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits. Deferred calls do not run, so it is only used before serving starts or
+// once serving has failed for good.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// serve runs srv until it is shut down, over HTTPS when a certificate is configured.
+func serve(srv *http.Server, tlsConfig config.TLSConfig) error {
+	var err error
+	if tlsConfig.Enabled() {
+		certs, loadErr := tlsreload.New(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if loadErr != nil {
+			return loadErr
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+		// SIGHUP picks up a renewed certificate right away
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := certs.Reload(); err != nil {
+					slog.Error("failed to reload TLS certificate", "error", err)
+				} else {
+					slog.Info("reloaded TLS certificate")
+				}
+			}
+		}()
+
+		slog.Info("server starting", "port", strings.TrimPrefix(srv.Addr, ":"), "tls", true)
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		slog.Info("server starting", "port", strings.TrimPrefix(srv.Addr, ":"), "tls", false)
+		err = srv.ListenAndServe()
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, handler))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,60 @@
+// Package apperror defines the typed errors the application reports to clients. Each error has
+// a kind, which decides the HTTP status, and a stable code whose message comes from the catalog.
+package apperror
+
+import "errors"
+
+type Kind string
+
+const (
+	KindValidation         Kind = "validation"
+	KindUnauthorized       Kind = "unauthorized"
+	KindNotFound           Kind = "not_found"
+	KindConflict           Kind = "conflict"
+	KindPreconditionFailed Kind = "precondition_failed"
+	KindUnsupportedMedia   Kind = "unsupported_media_type"
+	KindTooLarge           Kind = "too_large"
+	KindRateLimited        Kind = "rate_limited"
+	KindInternal           Kind = "internal"
+)
+
+// Error is a domain error. Fields maps request fields to the code of what is wrong with each.
+type Error struct {
+	Kind   Kind
+	Code   string
+	Fields map[string]string
+}
+
+func New(kind Kind, code string) *Error {
+	return &Error{Kind: kind, Code: code}
+}
+
+// Error returns the Indonesian message, which is what logs and older callers have always seen.
+func (e *Error) Error() string {
+	return Message(e.Code, DefaultLang)
+}
+
+// Is matches errors by code so a copy made by WithFields still compares equal to its sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithFields returns a copy of e that names the offending fields.
+func (e *Error) WithFields(fields map[string]string) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Fields: fields}
+}
+
+// As returns the *Error inside err, or ErrInternal when err is not a domain error.
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal
+}
+
+var (
+	ErrInternal     = New(KindInternal, "internal_error")
+	ErrInvalidInput = New(KindValidation, "invalid_fields")
+)
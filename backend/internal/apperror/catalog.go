@@ -0,0 +1,102 @@
+package apperror
+
+import "strings"
+
+const (
+	LangID      = "id"
+	LangEN      = "en"
+	DefaultLang = LangID
+)
+
+// catalog holds the client-facing message of every error and field code in each language.
+var catalog = map[string]map[string]string{
+	// General
+	"internal_error":         {LangID: "Terjadi kesalahan pada server", LangEN: "Something went wrong on the server"},
+	"invalid_fields":         {LangID: "Beberapa isian tidak valid", LangEN: "Some fields are invalid"},
+	"invalid_id":             {LangID: "ID tidak valid", LangEN: "Invalid ID"},
+	"invalid_json":           {LangID: "Format JSON tidak valid", LangEN: "Invalid JSON"},
+	"invalid_merge_patch":    {LangID: "JSON merge patch tidak valid", LangEN: "Invalid JSON merge patch"},
+	"unsupported_media_type": {LangID: "PATCH membutuhkan application/merge-patch+json", LangEN: "PATCH expects application/merge-patch+json"},
+	"invalid_if_match":       {LangID: "Header If-Match tidak valid", LangEN: "Invalid If-Match header"},
+	"version_conflict":       {LangID: "Data sudah diubah oleh admin lain; muat ulang lalu terapkan perubahan Anda lagi", LangEN: "Data was changed by someone else; reload and apply your changes again"},
+	"not_updated":            {LangID: "Data tidak ditemukan atau sudah diubah", LangEN: "The item was not found or has been changed"},
+	"body_too_large":         {LangID: "Isi permintaan terlalu besar", LangEN: "The request body is too large"},
+	"invalid_limit":          {LangID: "Nilai limit tidak valid", LangEN: "Invalid limit"},
+	"too_many_requests":      {LangID: "Terlalu banyak permintaan; coba lagi sebentar lagi", LangEN: "Too many requests; try again shortly"},
+
+	// Authentication
+	"authorization_required":       {LangID: "Header Authorization wajib diisi", LangEN: "Authorization header required"},
+	"invalid_authorization_header": {LangID: "Format header Authorization tidak valid", LangEN: "Invalid authorization header format"},
+	"invalid_token":                {LangID: "Token tidak valid", LangEN: "Invalid token"},
+	"invalid_credentials":          {LangID: "Username atau password salah", LangEN: "Invalid credentials"},
+
+	// Not found
+	"banner_not_found":   {LangID: "Banner tidak ditemukan", LangEN: "Banner not found"},
+	"kegiatan_not_found": {LangID: "Kegiatan tidak ditemukan", LangEN: "Kegiatan not found"},
+	"photo_not_found":    {LangID: "Foto tidak ditemukan", LangEN: "Photo not found"},
+	"struktur_not_found": {LangID: "Struktur tidak ditemukan", LangEN: "Struktur not found"},
+	"pembina_not_found":  {LangID: "Pembina tidak ditemukan", LangEN: "Pembina not found"},
+	"qrcode_not_found":   {LangID: "QR Code tidak ditemukan", LangEN: "QR Code not found"},
+	"category_not_found": {LangID: "Kategori tidak ditemukan", LangEN: "Category not found"},
+	"tag_not_found":      {LangID: "Tag tidak ditemukan", LangEN: "Tag not found"},
+	"revision_not_found": {LangID: "Revisi tidak ditemukan", LangEN: "Revision not found"},
+	"not_in_trash":       {LangID: "Data tidak ditemukan di tempat sampah", LangEN: "Item not found in the trash"},
+
+	// Conflicts
+	"pembina_limit_reached": {LangID: "Maksimal 2 pembina sudah tercapai", LangEN: "The maximum of 2 pembina has been reached"},
+	"category_exists":       {LangID: "Kategori dengan slug tersebut sudah ada", LangEN: "A category with this slug already exists"},
+	"tag_exists":            {LangID: "Tag dengan slug tersebut sudah ada", LangEN: "A tag with this slug already exists"},
+
+	// Validation
+	"kegiatan_status_invalid": {LangID: "Status kegiatan tidak valid", LangEN: "Invalid kegiatan status"},
+	"event_time_invalid":      {LangID: "Waktu mulai dan selesai kegiatan tidak valid", LangEN: "Invalid kegiatan start and end time"},
+	"timezone_invalid":        {LangID: "Zona waktu kegiatan tidak dikenal", LangEN: "Unknown kegiatan timezone"},
+	"coordinates_invalid":     {LangID: "Koordinat lokasi kegiatan tidak valid", LangEN: "Invalid kegiatan location coordinates"},
+	"category_name_required":  {LangID: "Nama kategori wajib diisi", LangEN: "Category name is required"},
+	"tag_name_required":       {LangID: "Nama tag wajib diisi", LangEN: "Tag name is required"},
+	"tag_name_too_long":       {LangID: "Nama tag maksimal 50 karakter", LangEN: "Tag names are at most 50 characters"},
+	"trash_type_invalid":      {LangID: "Jenis data di tempat sampah tidak valid", LangEN: "Invalid trash item type"},
+	"revision_range_invalid":  {LangID: "from dan to harus berupa versi revisi", LangEN: "from and to must be revision versions"},
+	"invalid_form":            {LangID: "Form tidak dapat dibaca", LangEN: "Unable to parse form"},
+	"file_required":           {LangID: "File wajib diunggah", LangEN: "No file provided"},
+	"file_too_large":          {LangID: "Ukuran file melebihi batas upload", LangEN: "The upload exceeds the size limit"},
+	"file_type_invalid":       {LangID: "Jenis file tidak valid. Hanya JPEG, PNG, dan GIF yang diizinkan", LangEN: "Invalid file type. Only JPEG, PNG, and GIF are allowed"},
+	"file_save_failed":        {LangID: "File tidak dapat disimpan", LangEN: "Unable to save file"},
+
+	// Field codes
+	"required":       {LangID: "wajib diisi", LangEN: "is required"},
+	"read_only":      {LangID: "tidak dapat diubah", LangEN: "cannot be changed"},
+	"invalid_type":   {LangID: "tipe data tidak sesuai", LangEN: "has the wrong type"},
+	"invalid_value":  {LangID: "nilai tidak valid", LangEN: "is not valid"},
+	"too_long":       {LangID: "melebihi panjang maksimal", LangEN: "is too long"},
+	"unknown_upload": {LangID: "harus berupa file yang sudah diunggah ke /uploads/", LangEN: "must be a file uploaded to /uploads/"},
+	"nra_format":     {LangID: "format NRA tidak valid, gunakan huruf dan angka seperti 2021.001", LangEN: "is not a valid NRA, use letters and digits such as 2021.001"},
+	"before_start":   {LangID: "tidak boleh sebelum waktu mulai", LangEN: "must not be before the start time"},
+}
+
+// Message returns the text for code in lang, falling back to Indonesian and then to the code.
+func Message(code, lang string) string {
+	messages, ok := catalog[code]
+	if !ok {
+		return code
+	}
+	if msg, ok := messages[lang]; ok {
+		return msg
+	}
+	return messages[DefaultLang]
+}
+
+// LangFromHeader picks the catalog language for an Accept-Language header. The first
+// supported language listed wins; without one the messages stay Indonesian.
+func LangFromHeader(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case tag == LangID || strings.HasPrefix(tag, LangID+"-"):
+			return LangID
+		case tag == LangEN || strings.HasPrefix(tag, LangEN+"-"):
+			return LangEN
+		}
+	}
+	return DefaultLang
+}
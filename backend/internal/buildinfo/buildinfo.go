@@ -0,0 +1,47 @@
+// Package buildinfo describes the running binary. Commit and BuildTime are set at build time:
+//
+//	go build -ldflags "-X arshaka-backend/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X arshaka-backend/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd
+package buildinfo
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/migrations"
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Get returns the build details. Without ldflags the commit and time recorded by the Go
+// toolchain are used when the binary was built from a git checkout.
+func Get() entity.BuildInfo {
+	info := entity.BuildInfo{
+		Commit:        Commit,
+		BuildTime:     BuildTime,
+		SchemaVersion: migrations.SchemaVersion(),
+		GoVersion:     runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
@@ -0,0 +1,575 @@
+// Package config loads the backend settings into typed structs. Values come from, in
+// increasing order of precedence: the defaults below, an optional YAML file, a .env file and
+// the process environment.
+package config
+
+import (
+	"arshaka-backend/pkg/database"
+	"arshaka-backend/pkg/logging"
+	"arshaka-backend/pkg/ratelimit"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is read when CONFIG_FILE is not set. Unlike an explicit CONFIG_FILE it may be missing.
+const DefaultFile = "config.yaml"
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Upload    UploadConfig    `yaml:"upload"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Trash     TrashConfig     `yaml:"trash"`
+	Log       LogConfig       `yaml:"log"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Cache     CacheConfig     `yaml:"cache"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Security  SecurityConfig  `yaml:"security"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" env:"PORT"`
+	// SiteURL is the public address of the website, used for links in feeds and previews
+	SiteURL string `yaml:"site_url" env:"SITE_URL"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	// ShutdownTimeout is how long in-flight requests may take to finish after SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	MaxHeaderBytes  int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// MaxBodyBytes caps non-upload request bodies; uploads use the Upload limits
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES"`
+
+	TLS TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig enables HTTPS when both files are set. The certificate is reloaded when the files
+// change or the process receives SIGHUP.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+}
+
+// Enabled reports whether the server should serve HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+}
+
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET"`
+	// BcryptCost is used when admin passwords are hashed
+	BcryptCost int `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+	// SessionTimeout is how long a login token stays valid
+	SessionTimeout time.Duration `yaml:"session_timeout" env:"SESSION_TIMEOUT"`
+}
+
+type UploadConfig struct {
+	Path string `yaml:"path" env:"UPLOAD_PATH"`
+	// MaxSize limits a single image upload, MaxBatchSize a multi-image upload, both in bytes
+	MaxSize      int64 `yaml:"max_size" env:"MAX_UPLOAD_SIZE"`
+	MaxBatchSize int64 `yaml:"max_batch_size" env:"MAX_UPLOAD_BATCH_SIZE"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"ALLOWED_ORIGINS"`
+}
+
+type TrashConfig struct {
+	RetentionDays int `yaml:"retention_days" env:"TRASH_RETENTION_DAYS"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is json, or text for reading logs in a terminal
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type MetricsConfig struct {
+	// Token, when set, must be sent as a bearer token to read /metrics
+	Token string `yaml:"token" env:"METRICS_TOKEN"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, otlp or stdout
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	// Endpoint is the OTLP/HTTP collector, e.g. http://localhost:4318
+	Endpoint string `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// SampleRatio is the share of new traces that are recorded, between 0 and 1
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+type CacheConfig struct {
+	// TTL is how long public API responses are served from memory; 0 turns the cache off
+	TTL time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	// MaxEntries caps the number of cached responses
+	MaxEntries int `yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+	// MaxAge is how long browsers may reuse public API responses before revalidating them;
+	// 0 makes them revalidate every time, which is answered with 304 while nothing changed
+	MaxAge time.Duration `yaml:"max_age" env:"CACHE_MAX_AGE"`
+}
+
+// RateLimitConfig sets the requests each client may make per minute, and how many of them at
+// once, for each group of routes. A rate of 0 turns the limit of the group off.
+type RateLimitConfig struct {
+	// Store is memory, or redis to share the limits between several backend instances
+	Store    string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	RedisURL string `yaml:"redis_url" env:"RATE_LIMIT_REDIS_URL"`
+	// TrustedProxies are the addresses or CIDR ranges whose X-Forwarded-For header is believed
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	PublicPerMinute int `yaml:"public_per_minute" env:"RATE_LIMIT_PUBLIC_PER_MINUTE"`
+	PublicBurst     int `yaml:"public_burst" env:"RATE_LIMIT_PUBLIC_BURST"`
+	LoginPerMinute  int `yaml:"login_per_minute" env:"RATE_LIMIT_LOGIN_PER_MINUTE"`
+	LoginBurst      int `yaml:"login_burst" env:"RATE_LIMIT_LOGIN_BURST"`
+	AdminPerMinute  int `yaml:"admin_per_minute" env:"RATE_LIMIT_ADMIN_PER_MINUTE"`
+	AdminBurst      int `yaml:"admin_burst" env:"RATE_LIMIT_ADMIN_BURST"`
+}
+
+// SecurityConfig decides the security headers sent with every response. FrameOptions and
+// ReferrerPolicy take "off" to leave the header out.
+type SecurityConfig struct {
+	// HSTS tells browsers to only use HTTPS for HSTSMaxAge; it is only sent while TLS is enabled
+	HSTS       bool          `yaml:"hsts" env:"SECURITY_HSTS"`
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+	// NoSniff sends X-Content-Type-Options: nosniff; uploads always get it
+	NoSniff bool `yaml:"nosniff" env:"SECURITY_NOSNIFF"`
+	// FrameOptions is DENY, SAMEORIGIN or off
+	FrameOptions   string `yaml:"frame_options" env:"SECURITY_FRAME_OPTIONS"`
+	ReferrerPolicy string `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+}
+
+// Default returns the settings used for anything left unconfigured.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			SiteURL:           "http://localhost:3000",
+			ReadTimeout:       60 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      20 << 20,
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     "3306",
+			User:     "arshaka_user",
+			Password: "arshaka_pass",
+			Name:     "arshaka_db",
+		},
+		Auth: AuthConfig{
+			JWTSecret:      "your-super-secret-jwt-key-here",
+			BcryptCost:     bcrypt.DefaultCost,
+			SessionTimeout: 24 * time.Hour,
+		},
+		Upload: UploadConfig{
+			Path:         "./uploads",
+			MaxSize:      10 << 20,
+			MaxBatchSize: 50 << 20,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000", "http://localhost:3001"},
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "arshaka-backend",
+			SampleRatio: 1,
+		},
+		Cache: CacheConfig{
+			TTL:        time.Minute,
+			MaxEntries: 1000,
+		},
+		RateLimit: RateLimitConfig{
+			Store:           "memory",
+			PublicPerMinute: 300,
+			PublicBurst:     100,
+			LoginPerMinute:  5,
+			LoginBurst:      10,
+			AdminPerMinute:  600,
+			AdminBurst:      200,
+		},
+		Security: SecurityConfig{
+			HSTS:           true,
+			HSTSMaxAge:     365 * 24 * time.Hour,
+			NoSniff:        true,
+			FrameOptions:   "DENY",
+			ReferrerPolicy: "strict-origin-when-cross-origin",
+		},
+	}
+}
+
+// Load reads .env into the environment, then builds the configuration from the defaults, the
+// YAML file named by CONFIG_FILE (or DefaultFile when present) and the environment, and
+// validates the result.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: read .env: %w", err)
+	}
+
+	cfg := Default()
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = DefaultFile
+	}
+	if err := cfg.loadYAML(path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadYAML(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides every field that has an env tag with the variable of that name, when set.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := t.Field(i).Tag.Get("env")
+		value, ok := os.LookupEnv(key)
+		if key == "" || !ok || value == "" {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate reports every setting that cannot work.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		add("PORT must be a port number, got %q", c.Server.Port)
+	}
+	if !isAbsoluteURL(c.Server.SiteURL) {
+		add("SITE_URL must be an absolute http(s) URL, got %q", c.Server.SiteURL)
+	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value <= 0 {
+			add("%s must be positive, got %s", t.name, t.value)
+		}
+	}
+	if c.Server.MaxHeaderBytes <= 0 || c.Server.MaxBodyBytes <= 0 {
+		add("SERVER_MAX_HEADER_BYTES and SERVER_MAX_BODY_BYTES must be positive")
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		add("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.Database.Host == "" || c.Database.Name == "" {
+		add("DB_HOST and DB_NAME are required")
+	}
+	if c.Auth.JWTSecret == "" {
+		add("JWT_SECRET is required")
+	}
+	if c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost {
+		add("BCRYPT_COST must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Auth.BcryptCost)
+	}
+	if c.Auth.SessionTimeout <= 0 {
+		add("SESSION_TIMEOUT must be positive, got %s", c.Auth.SessionTimeout)
+	}
+	if c.Upload.Path == "" {
+		add("UPLOAD_PATH is required")
+	}
+	if c.Upload.MaxSize <= 0 {
+		add("MAX_UPLOAD_SIZE must be positive, got %d", c.Upload.MaxSize)
+	}
+	if c.Upload.MaxBatchSize < c.Upload.MaxSize {
+		add("MAX_UPLOAD_BATCH_SIZE must be at least MAX_UPLOAD_SIZE, got %d", c.Upload.MaxBatchSize)
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("ALLOWED_ORIGINS needs at least one origin")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin != "*" && !isAbsoluteURL(origin) {
+			add("ALLOWED_ORIGINS entry %q is not an http(s) origin", origin)
+		}
+	}
+	if c.Trash.RetentionDays < 1 {
+		add("TRASH_RETENTION_DAYS must be at least 1, got %d", c.Trash.RetentionDays)
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		add("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		add("LOG_FORMAT must be json or text, got %q", c.Log.Format)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		add("OTEL_TRACES_EXPORTER must be none, otlp or stdout, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" && !isAbsoluteURL(c.Tracing.Endpoint) {
+		add("OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL, got %q", c.Tracing.Endpoint)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+	if c.Cache.TTL < 0 {
+		add("CACHE_TTL must not be negative, got %s", c.Cache.TTL)
+	}
+	if c.Cache.MaxAge < 0 {
+		add("CACHE_MAX_AGE must not be negative, got %s", c.Cache.MaxAge)
+	}
+	if c.Cache.MaxEntries < 1 {
+		add("CACHE_MAX_ENTRIES must be at least 1, got %d", c.Cache.MaxEntries)
+	}
+	switch c.RateLimit.Store {
+	case "memory":
+	case "redis":
+		if u, err := url.Parse(c.RateLimit.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			add("RATE_LIMIT_REDIS_URL must be a redis:// or rediss:// URL, got %q", c.RateLimit.RedisURL)
+		}
+	default:
+		add("RATE_LIMIT_STORE must be memory or redis, got %q", c.RateLimit.Store)
+	}
+	for _, proxy := range c.RateLimit.TrustedProxies {
+		if _, err := parsePrefix(proxy); err != nil {
+			add("TRUSTED_PROXIES entry %q is not an IP address or CIDR range", proxy)
+		}
+	}
+	rateLimits := []struct {
+		name             string
+		perMinute, burst int
+	}{
+		{"PUBLIC", c.RateLimit.PublicPerMinute, c.RateLimit.PublicBurst},
+		{"LOGIN", c.RateLimit.LoginPerMinute, c.RateLimit.LoginBurst},
+		{"ADMIN", c.RateLimit.AdminPerMinute, c.RateLimit.AdminBurst},
+	}
+	for _, l := range rateLimits {
+		if l.perMinute < 0 {
+			add("RATE_LIMIT_%s_PER_MINUTE must not be negative, got %d", l.name, l.perMinute)
+		}
+		if l.perMinute > 0 && l.burst < 1 {
+			add("RATE_LIMIT_%s_BURST must be at least 1, got %d", l.name, l.burst)
+		}
+	}
+	if c.Security.HSTS && c.Security.HSTSMaxAge < time.Second {
+		add("SECURITY_HSTS_MAX_AGE must be at least 1s, got %s", c.Security.HSTSMaxAge)
+	}
+	switch c.Security.FrameOptions {
+	case "DENY", "SAMEORIGIN", "off":
+	default:
+		add("SECURITY_FRAME_OPTIONS must be DENY, SAMEORIGIN or off, got %q", c.Security.FrameOptions)
+	}
+	if !referrerPolicies[c.Security.ReferrerPolicy] {
+		add("SECURITY_REFERRER_POLICY must be a Referrer-Policy value or off, got %q", c.Security.ReferrerPolicy)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Connection returns the settings in the form pkg/database expects.
+func (c DatabaseConfig) Connection() database.Config {
+	return database.Config{
+		Host:     c.Host,
+		Port:     c.Port,
+		User:     c.User,
+		Password: c.Password,
+		DBName:   c.Name,
+	}
+}
+
+// Logger builds the logger described by the settings. Validate has already checked the level.
+func (c LogConfig) Logger() *slog.Logger {
+	level, _ := logging.ParseLevel(c.Level)
+	return logging.New(os.Stdout, level, c.Format)
+}
+
+// Retention is how long deleted content stays in the trash.
+func (c TrashConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// Public, Login and Admin return the limit of each route group in the form pkg/ratelimit expects.
+func (c RateLimitConfig) Public() ratelimit.Limit {
+	return ratelimit.Limit{PerMinute: c.PublicPerMinute, Burst: c.PublicBurst}
+}
+
+func (c RateLimitConfig) Login() ratelimit.Limit {
+	return ratelimit.Limit{PerMinute: c.LoginPerMinute, Burst: c.LoginBurst}
+}
+
+func (c RateLimitConfig) Admin() ratelimit.Limit {
+	return ratelimit.Limit{PerMinute: c.AdminPerMinute, Burst: c.AdminBurst}
+}
+
+// Proxies returns the trusted proxies as prefixes; a single address becomes a /32 or /128.
+// Validate has already checked every entry.
+func (c RateLimitConfig) Proxies() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		if prefix, err := parsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+func parsePrefix(raw string) (netip.Prefix, error) {
+	if strings.Contains(raw, "/") {
+		prefix, err := netip.ParsePrefix(raw)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(raw)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+var referrerPolicies = map[string]bool{
+	"no-referrer": true, "no-referrer-when-downgrade": true, "origin": true,
+	"origin-when-cross-origin": true, "same-origin": true, "strict-origin": true,
+	"strict-origin-when-cross-origin": true, "unsafe-url": true, "off": true,
+}
+
+// Headers returns the security headers to send with every response. HSTS is left out unless the
+// server itself serves HTTPS, since browsers ignore it over plain HTTP.
+func (c SecurityConfig) Headers(tls bool) map[string]string {
+	headers := map[string]string{}
+	if c.HSTS && tls {
+		headers["Strict-Transport-Security"] = fmt.Sprintf("max-age=%d; includeSubDomains", int64(c.HSTSMaxAge.Seconds()))
+	}
+	if c.NoSniff {
+		headers["X-Content-Type-Options"] = "nosniff"
+	}
+	if c.FrameOptions != "off" {
+		headers["X-Frame-Options"] = c.FrameOptions
+	}
+	if c.ReferrerPolicy != "off" {
+		headers["Referrer-Policy"] = c.ReferrerPolicy
+	}
+	return headers
+}
+
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
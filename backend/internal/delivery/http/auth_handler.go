@@ -2,37 +2,42 @@ package http
 
 import (
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/metrics"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 	"encoding/json"
 	"net/http"
 )
 
 type AuthHandler struct {
 	authUsecase usecase.AuthUsecase
+	validator   *validation.Validator
+	metrics     *metrics.Metrics
 }
 
-func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
+func NewAuthHandler(authUsecase usecase.AuthUsecase, validator *validation.Validator, m *metrics.Metrics) *AuthHandler {
 	return &AuthHandler{
 		authUsecase: authUsecase,
+		validator:   validator,
+		metrics:     m,
 	}
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req entity.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
-	// Basic validation
-	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+	if !validRequest(w, r, h.validator, &req) {
 		return
 	}
 
 	resp, err := h.authUsecase.Login(r.Context(), &req)
+	h.metrics.ObserveLogin(err)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, r, err)
 		return
 	}
 
@@ -2,7 +2,9 @@ package http
 
 import (
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -12,18 +14,20 @@ import (
 
 type BannerHandler struct {
 	bannerUsecase usecase.BannerUsecase
+	validator     *validation.Validator
 }
 
-func NewBannerHandler(bannerUsecase usecase.BannerUsecase) *BannerHandler {
+func NewBannerHandler(bannerUsecase usecase.BannerUsecase, validator *validation.Validator) *BannerHandler {
 	return &BannerHandler{
 		bannerUsecase: bannerUsecase,
+		validator:     validator,
 	}
 }
 
 func (h *BannerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	banners, err := h.bannerUsecase.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -38,21 +42,22 @@ func (h *BannerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	banner, err := h.bannerUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if banner == nil {
-		http.Error(w, "Banner not found", http.StatusNotFound)
+		writeError(w, r, errBannerNotFound)
 		return
 	}
 
+	setETag(w, banner.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -63,12 +68,15 @@ func (h *BannerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *BannerHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var banner entity.Banner
 	if err := json.NewDecoder(r.Body).Decode(&banner); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
+	if !validRequest(w, r, h.validator, &banner) {
+		return
+	}
 	if err := h.bannerUsecase.Create(r.Context(), &banner); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -85,22 +93,43 @@ func (h *BannerHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var banner entity.Banner
 	if err := json.NewDecoder(r.Body).Decode(&banner); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
 	banner.ID = id
+	if banner.Version, err = ifMatchVersion(r, banner.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	current, err := h.bannerUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errBannerNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, &banner, current) {
+		return
+	}
 	if err := h.bannerUsecase.Update(r.Context(), &banner); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.bannerUsecase.GetByID, id, errBannerNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, banner.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -113,12 +142,15 @@ func (h *BannerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.bannerUsecase.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errBannerNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -128,3 +160,27 @@ func (h *BannerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Banner deleted successfully",
 	})
 }
+
+// bannerPatchFields are the members a PATCH may change. "version" works like If-Match.
+var bannerPatchFields = patchFields{
+	"image_url": requiredString,
+	"version":   nil,
+}
+
+// Patch applies a JSON merge patch to a banner and returns it as stored afterwards.
+func (h *BannerHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Banner]{
+		fields:   bannerPatchFields,
+		load:     h.bannerUsecase.GetByID,
+		update:   h.bannerUsecase.Update,
+		notFound: errBannerNotFound,
+		message:  "Banner updated successfully",
+	})
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"arshaka-backend/pkg/cache"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// cacheBusterParam is the query parameter clients add to dodge browser caches; it does not change
+// the response and is left out of the cache key.
+const cacheBusterParam = "t"
+
+type CacheHandler struct {
+	cache        *cache.Cache
+	ttl          time.Duration
+	cacheControl string
+}
+
+// cachedResponse is a 200 response as the handler wrote it, with the validators derived from it.
+type cachedResponse struct {
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified time.Time
+}
+
+// NewCacheHandler serves public GETs from c for ttl; a ttl of 0 leaves every request to its
+// handler. Browsers may reuse a response for maxAge and revalidate it after that.
+func NewCacheHandler(c *cache.Cache, ttl, maxAge time.Duration) *CacheHandler {
+	cacheControl := "public, no-cache"
+	if maxAge > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	}
+	return &CacheHandler{
+		cache:        c,
+		ttl:          ttl,
+		cacheControl: cacheControl,
+	}
+}
+
+// Public answers from the cache when it holds the response for the path and query, and otherwise
+// caches what next answers with 200. group names the data the response shows, so the usecases
+// can drop it once that data changes. X-Cache tells HIT from MISS.
+//
+// Every 200 carries a weak ETag hashed from the body, weak because Compress serves the same body
+// in several encodings, and the last time group was invalidated as Last-Modified. Kegiatan
+// groups are also invalidated when a kegiatan goes public, starts or ends, since no edit marks it. Requests whose
+// If-None-Match or If-Modified-Since still match are answered with 304 and no body.
+func (h *CacheHandler) Public(group string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := cacheKey(r)
+		if h.ttl > 0 {
+			if value, ok := h.cache.Get(group, key); ok {
+				w.Header().Set("X-Cache", "HIT")
+				h.write(w, r, value.(*cachedResponse))
+				return
+			}
+		}
+
+		// Read before the handler queries, so a change made meanwhile keeps its result out and
+		// a newer Last-Modified is not paired with older data
+		generation := h.cache.Generation(group)
+		changed := h.cache.Changed(group)
+		rec := &cacheRecorder{header: make(http.Header), status: http.StatusOK}
+		next(rec, r)
+		if rec.status != http.StatusOK {
+			copyHeader(w.Header(), rec.header)
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		response := newCachedResponse(rec.header, rec.body.Bytes(), changed)
+		if h.ttl > 0 {
+			h.cache.Set(group, key, generation, response, h.ttl)
+			w.Header().Set("X-Cache", "MISS")
+		}
+		h.write(w, r, response)
+	}
+}
+
+func (h *CacheHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    h.cache.Stats(),
+	})
+}
+
+// newCachedResponse derives the validators of body, whose data last changed at changed. Last-Modified
+// has second precision.
+func newCachedResponse(header http.Header, body []byte, changed time.Time) *cachedResponse {
+	sum := sha256.Sum256(body)
+	return &cachedResponse{
+		header:       header,
+		body:         body,
+		etag:         `W/"` + hex.EncodeToString(sum[:16]) + `"`,
+		lastModified: changed.UTC().Truncate(time.Second),
+	}
+}
+
+// write sends response, or only its validators when the client's copy is still current.
+func (h *CacheHandler) write(w http.ResponseWriter, r *http.Request, response *cachedResponse) {
+	status := http.StatusNotModified
+	if !notModified(r, response) {
+		status = http.StatusOK
+		copyHeader(w.Header(), response.header)
+	}
+
+	w.Header().Set("ETag", response.etag)
+	if !response.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", response.lastModified.Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", h.cacheControl)
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		w.Write(response.body)
+	}
+}
+
+// notModified evaluates the request's validators against response. If-Modified-Since is only
+// consulted without If-None-Match, as RFC 9110 requires.
+func notModified(r *http.Request, response *cachedResponse) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, response.etag)
+	}
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || response.lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	return err == nil && !response.lastModified.After(since)
+}
+
+// etagListMatches compares etag weakly with every tag in an If-None-Match header.
+func etagListMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey is the path with the query in canonical order, so ?a=1&b=2 and ?b=2&a=1 share an entry.
+func cacheKey(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del(cacheBusterParam)
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + query.Encode()
+}
+
+func copyHeader(dst, src http.Header) {
+	for name, values := range src {
+		dst[name] = values
+	}
+}
+
+// cacheRecorder holds what the handler writes until Public has decided how to answer.
+type cacheRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *cacheRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *cacheRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *cacheRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/usecase"
+	"arshaka-backend/pkg/ical"
+	"arshaka-backend/pkg/markdown"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// calendarPastWindow keeps recently finished kegiatan in the feed so calendars do not drop them right away.
+const calendarPastWindow = 90 * 24 * time.Hour
+
+type CalendarHandler struct {
+	kegiatanUsecase usecase.KegiatanUsecase
+	siteURL         string
+}
+
+func NewCalendarHandler(kegiatanUsecase usecase.KegiatanUsecase, siteURL string) *CalendarHandler {
+	return &CalendarHandler{
+		kegiatanUsecase: kegiatanUsecase,
+		siteURL:         strings.TrimSuffix(siteURL, "/"),
+	}
+}
+
+// Feed serves published kegiatan as an iCalendar subscription, optionally filtered with ?category= or ?tag=.
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	filter := entity.KegiatanFilter{
+		PublishedOnly: true,
+		CategorySlug:  r.URL.Query().Get("category"),
+		TagSlug:       r.URL.Query().Get("tag"),
+		EndsAfter:     time.Now().Add(-calendarPastWindow),
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	cal := h.newCalendar("Kegiatan Arshaka Bimantara")
+	for i := range kegiatan {
+		cal.Events = append(cal.Events, h.toEvent(&kegiatan[i]))
+	}
+
+	h.write(w, cal, "")
+}
+
+// Event serves a single kegiatan as a downloadable .ics file.
+func (h *CalendarHandler) Event(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if kegiatan == nil || !kegiatan.IsPublic(time.Now()) {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+
+	cal := h.newCalendar(kegiatan.Judul)
+	cal.Events = append(cal.Events, h.toEvent(kegiatan))
+
+	filename := kegiatan.Slug
+	if filename == "" {
+		filename = fmt.Sprintf("kegiatan-%d", kegiatan.ID)
+	}
+	h.write(w, cal, filename+".ics")
+}
+
+func (h *CalendarHandler) newCalendar(name string) *ical.Calendar {
+	return &ical.Calendar{
+		ProdID:   "-//Arshaka Bimantara//Kegiatan//ID",
+		Name:     name,
+		Timezone: entity.DefaultTimezone,
+	}
+}
+
+func (h *CalendarHandler) toEvent(k *entity.Kegiatan) ical.Event {
+	location := k.Location.Name
+	if k.Location.Address != "" {
+		if location != "" {
+			location += ", "
+		}
+		location += k.Location.Address
+	}
+
+	categories := make([]string, 0, len(k.Categories))
+	for _, c := range k.Categories {
+		categories = append(categories, c.Name)
+	}
+
+	return ical.Event{
+		// The UID only depends on the ID so calendar apps update the event instead of duplicating it
+		UID:          fmt.Sprintf("kegiatan-%d@%s", k.ID, h.uidDomain()),
+		Summary:      k.Judul,
+		Description:  markdown.PlainText(k.Deskripsi),
+		Location:     location,
+		Latitude:     k.Location.Latitude,
+		Longitude:    k.Location.Longitude,
+		URL:          kegiatanURL(h.siteURL, k),
+		Categories:   categories,
+		Start:        k.StartAt,
+		End:          k.EndAt,
+		Created:      k.CreatedAt,
+		LastModified: k.UpdatedAt,
+		Sequence:     int(k.UpdatedAt.Sub(k.CreatedAt) / time.Second),
+	}
+}
+
+func (h *CalendarHandler) uidDomain() string {
+	if u, err := url.Parse(h.siteURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "arshaka-bimantara"
+}
+
+func (h *CalendarHandler) write(w http.ResponseWriter, cal *ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	cal.Encode(w)
+}
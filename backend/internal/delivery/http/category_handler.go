@@ -0,0 +1,183 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type CategoryHandler struct {
+	categoryUsecase usecase.CategoryUsecase
+	validator       *validation.Validator
+}
+
+func NewCategoryHandler(categoryUsecase usecase.CategoryUsecase, validator *validation.Validator) *CategoryHandler {
+	return &CategoryHandler{
+		categoryUsecase: categoryUsecase,
+		validator:       validator,
+	}
+}
+
+func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	// Visitors only see counts of published kegiatan
+	_, isAdmin := GetUserFromContext(r.Context())
+
+	categories, err := h.categoryUsecase.GetAll(r.Context(), !isAdmin)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if categories == nil {
+		categories = []entity.Category{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    categories,
+	})
+}
+
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	category, err := h.categoryUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if category == nil {
+		writeError(w, r, errCategoryNotFound)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    category,
+	})
+}
+
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var category entity.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeBodyError(w, r, err, errInvalidJSON)
+		return
+	}
+
+	if !validRequest(w, r, h.validator, &category) {
+		return
+	}
+	if err := h.categoryUsecase.Create(r.Context(), &category); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    category,
+		"message": "Category created successfully",
+	})
+}
+
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	var category entity.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		writeBodyError(w, r, err, errInvalidJSON)
+		return
+	}
+
+	category.ID = id
+	if category.Version, err = ifMatchVersion(r, category.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	if !validRequest(w, r, h.validator, &category) {
+		return
+	}
+	if err := h.categoryUsecase.Update(r.Context(), &category); err != nil {
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.categoryUsecase.GetByID, id, errCategoryNotFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    category,
+		"message": "Category updated successfully",
+	})
+}
+
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.categoryUsecase.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Category deleted successfully",
+	})
+}
+
+// categoryPatchFields are the members a PATCH may change. "version" works like If-Match.
+var categoryPatchFields = patchFields{
+	"name":        requiredString,
+	"slug":        nil,
+	"description": nil,
+	"version":     nil,
+}
+
+// Patch applies a JSON merge patch to a category and returns it as stored afterwards.
+func (h *CategoryHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Category]{
+		fields:   categoryPatchFields,
+		load:     h.categoryUsecase.GetByID,
+		update:   h.categoryUsecase.Update,
+		notFound: errCategoryNotFound,
+		message:  "Category updated successfully",
+	})
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+var brotliWriters = sync.Pool{
+	New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	},
+}
+
+// Compress encodes JSON responses with brotli or gzip, whichever the client prefers in
+// Accept-Encoding. Other content, such as uploaded images, is already compressed or small and is
+// passed through unchanged.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks br over gzip when both are acceptable, honouring q=0 exclusions.
+func negotiateEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q > 0
+	}
+	for _, encoding := range []string{"br", "gzip"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter decides on the first WriteHeader or Write whether the response is compressed.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	decided  bool
+	encoder  io.WriteCloser
+	release  func()
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.decide(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) decide(status int) {
+	w.decided = true
+	header := w.Header()
+	if !isJSON(header.Get("Content-Type")) {
+		return
+	}
+	header.Add("Vary", "Accept-Encoding")
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		header.Get("Content-Encoding") != "" {
+		return
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	switch w.encoding {
+	case "br":
+		bw := brotliWriters.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		w.encoder = bw
+		w.release = func() { brotliWriters.Put(bw) }
+	default:
+		gw := gzipWriters.Get().(*gzip.Writer)
+		gw.Reset(w.ResponseWriter)
+		w.encoder = gw
+		w.release = func() { gzipWriters.Put(gw) }
+	}
+}
+
+// Close flushes the encoder once the handler is done.
+func (w *compressWriter) Close() {
+	if w.encoder == nil {
+		return
+	}
+	w.encoder.Close()
+	w.release()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// isJSON reports whether contentType is application/json or a +json type such as
+// application/problem+json.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
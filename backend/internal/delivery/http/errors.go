@@ -0,0 +1,111 @@
+package http
+
+import (
+	"arshaka-backend/internal/apperror"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// Errors raised by the HTTP layer itself. Domain errors come typed from the usecases and
+// repositories and go through the same writeError.
+var (
+	errInvalidID                 = apperror.New(apperror.KindValidation, "invalid_id")
+	errInvalidJSON               = apperror.New(apperror.KindValidation, "invalid_json")
+	errInvalidMergePatch         = apperror.New(apperror.KindValidation, "invalid_merge_patch")
+	errUnsupportedMediaType      = apperror.New(apperror.KindUnsupportedMedia, "unsupported_media_type")
+	errInvalidIfMatch            = apperror.New(apperror.KindValidation, "invalid_if_match")
+	errInvalidLimit              = apperror.New(apperror.KindValidation, "invalid_limit")
+	errInvalidRevisionRange      = apperror.New(apperror.KindValidation, "revision_range_invalid")
+	errAuthorizationRequired     = apperror.New(apperror.KindUnauthorized, "authorization_required")
+	errInvalidAuthorizationValue = apperror.New(apperror.KindUnauthorized, "invalid_authorization_header")
+	errInvalidToken              = apperror.New(apperror.KindUnauthorized, "invalid_token")
+	errInvalidForm               = apperror.New(apperror.KindValidation, "invalid_form")
+	errFileRequired              = apperror.New(apperror.KindValidation, "file_required")
+	errFileTooLarge              = apperror.New(apperror.KindTooLarge, "file_too_large")
+	errBodyTooLarge              = apperror.New(apperror.KindTooLarge, "body_too_large")
+	errInvalidFileType           = apperror.New(apperror.KindValidation, "file_type_invalid")
+	errFileSaveFailed            = apperror.New(apperror.KindInternal, "file_save_failed")
+	errVersionConflict           = apperror.New(apperror.KindPreconditionFailed, "version_conflict")
+	errTooManyRequests           = apperror.New(apperror.KindRateLimited, "too_many_requests")
+
+	errBannerNotFound   = apperror.New(apperror.KindNotFound, "banner_not_found")
+	errKegiatanNotFound = apperror.New(apperror.KindNotFound, "kegiatan_not_found")
+	errPhotoNotFound    = apperror.New(apperror.KindNotFound, "photo_not_found")
+	errStrukturNotFound = apperror.New(apperror.KindNotFound, "struktur_not_found")
+	errPembinaNotFound  = apperror.New(apperror.KindNotFound, "pembina_not_found")
+	errQRCodeNotFound   = apperror.New(apperror.KindNotFound, "qrcode_not_found")
+	errCategoryNotFound = apperror.New(apperror.KindNotFound, "category_not_found")
+	errTagNotFound      = apperror.New(apperror.KindNotFound, "tag_not_found")
+)
+
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindValidation:         http.StatusBadRequest,
+	apperror.KindUnauthorized:       http.StatusUnauthorized,
+	apperror.KindNotFound:           http.StatusNotFound,
+	apperror.KindConflict:           http.StatusConflict,
+	apperror.KindPreconditionFailed: http.StatusPreconditionFailed,
+	apperror.KindUnsupportedMedia:   http.StatusUnsupportedMediaType,
+	apperror.KindTooLarge:           http.StatusRequestEntityTooLarge,
+	apperror.KindRateLimited:        http.StatusTooManyRequests,
+	apperror.KindInternal:           http.StatusInternalServerError,
+}
+
+// writeError answers with the JSON error envelope {success:false, error:{code, message, fields}}.
+// Anything that is not a domain error is logged and reported as a generic internal error, so
+// database details never reach the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperror.As(err)
+	if appErr.Kind == apperror.KindInternal {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+	writeErrorBody(w, r, appErr, nil)
+}
+
+// writeBodyError answers a request body that could not be decoded: 413 when it went over the
+// size cap, otherwise fallback.
+func writeBodyError(w http.ResponseWriter, r *http.Request, err error, fallback *apperror.Error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, errBodyTooLarge)
+		return
+	}
+	writeError(w, r, fallback)
+}
+
+// writeErrorBody writes the envelope for appErr, adding data when the client needs the current
+// state of the resource to recover.
+func writeErrorBody(w http.ResponseWriter, r *http.Request, appErr *apperror.Error, data interface{}) {
+	lang := apperror.LangFromHeader(r.Header.Get("Accept-Language"))
+
+	body := map[string]interface{}{
+		"code":    appErr.Code,
+		"message": apperror.Message(appErr.Code, lang),
+	}
+	if len(appErr.Fields) > 0 {
+		fields := make(map[string]string, len(appErr.Fields))
+		for field, code := range appErr.Fields {
+			fields[field] = apperror.Message(code, lang)
+		}
+		body["fields"] = fields
+	}
+
+	response := map[string]interface{}{
+		"success": false,
+		"error":   body,
+	}
+	if data != nil {
+		response["data"] = data
+	}
+
+	status, ok := statusByKind[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", lang)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
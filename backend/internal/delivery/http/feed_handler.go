@@ -0,0 +1,176 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/usecase"
+	"arshaka-backend/pkg/feed"
+	"arshaka-backend/pkg/markdown"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	feedEntryLimit    = 20
+	feedSummaryLength = 300
+)
+
+type FeedHandler struct {
+	kegiatanUsecase usecase.KegiatanUsecase
+	siteURL         string
+	// siteHost is the authority of the tag URIs that identify feed entries
+	siteHost string
+}
+
+func NewFeedHandler(kegiatanUsecase usecase.KegiatanUsecase, siteURL string) *FeedHandler {
+	siteHost := "localhost"
+	if u, err := url.Parse(siteURL); err == nil && u.Hostname() != "" {
+		siteHost = u.Hostname()
+	}
+	return &FeedHandler{
+		kegiatanUsecase: kegiatanUsecase,
+		siteURL:         strings.TrimSuffix(siteURL, "/"),
+		siteHost:        siteHost,
+	}
+}
+
+// Atom serves the most recent published kegiatan as an Atom feed.
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	kegiatan, err := h.kegiatanUsecase.GetAll(r.Context(), entity.KegiatanFilter{PublishedOnly: true})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(kegiatan) > feedEntryLimit {
+		kegiatan = kegiatan[:feedEntryLimit]
+	}
+
+	atom := &feed.AtomFeed{
+		ID:    h.siteURL + "/",
+		Title: "Kegiatan Arshaka Bimantara",
+		Links: []feed.AtomLink{
+			{Rel: "self", Href: h.siteURL + "/feed.xml", Type: "application/atom+xml"},
+			{Rel: "alternate", Href: h.siteURL + "/", Type: "text/html"},
+		},
+		Author: &feed.AtomPerson{Name: "Arshaka Bimantara"},
+	}
+
+	var updated time.Time
+	for i := range kegiatan {
+		k := &kegiatan[i]
+		if k.UpdatedAt.After(updated) {
+			updated = k.UpdatedAt
+		}
+		atom.Entries = append(atom.Entries, h.toEntry(k))
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	atom.Updated = feed.FormatTime(updated)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	atom.Encode(w)
+}
+
+// Sitemap lists the home page and every published kegiatan together with its images.
+func (h *FeedHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	kegiatan, err := h.kegiatanUsecase.GetAll(r.Context(), entity.KegiatanFilter{PublishedOnly: true})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	home := feed.SitemapURL{Loc: h.siteURL + "/", ChangeFreq: "weekly", Priority: "1.0"}
+	sitemap := &feed.Sitemap{}
+
+	var lastUpdate time.Time
+	for i := range kegiatan {
+		k := &kegiatan[i]
+		if k.UpdatedAt.After(lastUpdate) {
+			lastUpdate = k.UpdatedAt
+		}
+
+		entry := feed.SitemapURL{
+			Loc:        kegiatanURL(h.siteURL, k),
+			LastMod:    feed.FormatDate(k.UpdatedAt),
+			ChangeFreq: "monthly",
+			Priority:   "0.8",
+		}
+		if k.Cover != "" {
+			entry.Images = append(entry.Images, feed.SitemapImage{Loc: absoluteURL(h.siteURL, k.Cover)})
+		}
+		for _, foto := range k.Fotos {
+			entry.Images = append(entry.Images, feed.SitemapImage{Loc: absoluteURL(h.siteURL, foto.ImageURL)})
+		}
+		sitemap.URLs = append(sitemap.URLs, entry)
+	}
+
+	if !lastUpdate.IsZero() {
+		home.LastMod = feed.FormatDate(lastUpdate)
+	}
+	sitemap.URLs = append([]feed.SitemapURL{home}, sitemap.URLs...)
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	sitemap.Encode(w)
+}
+
+// entryID is a tag URI (RFC 4151) built from the kegiatan ID and creation date. Unlike the link
+// it stays the same when the slug changes, so readers do not show a renamed kegiatan again.
+func (h *FeedHandler) entryID(k *entity.Kegiatan) string {
+	return fmt.Sprintf("tag:%s,%s:kegiatan/%d", h.siteHost, k.CreatedAt.UTC().Format("2006-01-02"), k.ID)
+}
+
+func (h *FeedHandler) toEntry(k *entity.Kegiatan) feed.AtomEntry {
+	link := kegiatanURL(h.siteURL, k)
+	entry := feed.AtomEntry{
+		ID:      h.entryID(k),
+		Title:   k.Judul,
+		Updated: feed.FormatTime(k.UpdatedAt),
+		Links:   []feed.AtomLink{{Rel: "alternate", Href: link, Type: "text/html"}},
+	}
+
+	published := k.CreatedAt
+	if k.PublishAt != nil {
+		published = *k.PublishAt
+	}
+	entry.Published = feed.FormatTime(published)
+
+	if k.Cover != "" {
+		entry.Links = append(entry.Links, feed.AtomLink{
+			Rel:  "enclosure",
+			Href: absoluteURL(h.siteURL, k.Cover),
+			Type: mime.TypeByExtension(strings.ToLower(filepath.Ext(k.Cover))),
+		})
+	}
+
+	if summary := summarize(k.Deskripsi, feedSummaryLength); summary != "" {
+		entry.Summary = &feed.AtomText{Type: "text", Body: summary}
+	}
+
+	for _, c := range k.Categories {
+		entry.Categories = append(entry.Categories, feed.AtomCategory{Term: c.Slug, Label: c.Name})
+	}
+
+	return entry
+}
+
+// summarize strips Markdown formatting, collapses whitespace and shortens the description
+// to at most max characters at a word boundary.
+func summarize(deskripsi string, max int) string {
+	text := strings.Join(strings.Fields(markdown.PlainText(deskripsi)), " ")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
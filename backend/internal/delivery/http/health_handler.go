@@ -0,0 +1,57 @@
+package http
+
+import (
+	"arshaka-backend/internal/buildinfo"
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds all readiness checks together so a hung database fails the probe instead
+// of stalling it.
+const readyTimeout = 5 * time.Second
+
+// HealthHandler answers probes from Docker, load balancers and deploy scripts. Responses are
+// plain JSON without the API envelope.
+type HealthHandler struct {
+	healthUsecase usecase.HealthUsecase
+}
+
+func NewHealthHandler(healthUsecase usecase.HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		healthUsecase: healthUsecase,
+	}
+}
+
+// Healthz reports that the process is running and serving requests. It checks nothing else, so a
+// database outage does not get the container restarted.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, map[string]string{"status": entity.HealthStatusOK})
+}
+
+// Readyz answers 503 with the failing checks while the server cannot do its work.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	readiness := h.healthUsecase.Ready(ctx)
+	status := http.StatusOK
+	if readiness.Status != entity.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeProbe(w, status, readiness)
+}
+
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, buildinfo.Get())
+}
+
+func writeProbe(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
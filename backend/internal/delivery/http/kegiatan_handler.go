@@ -2,37 +2,68 @@ package http
 
 import (
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
+	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type KegiatanHandler struct {
 	kegiatanUsecase usecase.KegiatanUsecase
+	validator       *validation.Validator
 }
 
-func NewKegiatanHandler(kegiatanUsecase usecase.KegiatanUsecase) *KegiatanHandler {
+func NewKegiatanHandler(kegiatanUsecase usecase.KegiatanUsecase, validator *validation.Validator) *KegiatanHandler {
 	return &KegiatanHandler{
 		kegiatanUsecase: kegiatanUsecase,
+		validator:       validator,
 	}
 }
 
 func (h *KegiatanHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	log.Printf("GetAll kegiatan request received")
-	kegiatan, err := h.kegiatanUsecase.GetAll(r.Context())
+	// Visitors only see published kegiatan; admins see every status
+	_, isAdmin := GetUserFromContext(r.Context())
+	filter := entity.KegiatanFilter{
+		PublishedOnly: !isAdmin,
+		CategorySlug:  r.URL.Query().Get("category"),
+		TagSlug:       r.URL.Query().Get("tag"),
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetAll(r.Context(), filter)
 	if err != nil {
-		log.Printf("Error getting kegiatan: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	log.Printf("Found %d kegiatan", len(kegiatan))
-	for i, k := range kegiatan {
-		log.Printf("Kegiatan %d: ID=%d, Judul=%s, Fotos=%d", i, k.ID, k.Judul, len(k.Fotos))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    kegiatan,
+	})
+}
+
+func (h *KegiatanHandler) GetUpcoming(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 0 {
+			writeError(w, r, errInvalidLimit)
+			return
+		}
+		limit = parsed
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetUpcoming(r.Context(), limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -46,18 +77,69 @@ func (h *KegiatanHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	kegiatan, err := h.kegiatanUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	if kegiatan == nil {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+
+	if _, isAdmin := GetUserFromContext(r.Context()); !isAdmin && !kegiatan.IsPublic(time.Now()) {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+
+	setETag(w, kegiatan.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    kegiatan,
+	})
+}
+
+func (h *KegiatanHandler) GetBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	kegiatan, err := h.kegiatanUsecase.GetBySlug(r.Context(), slug)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	if kegiatan == nil {
-		http.Error(w, "Kegiatan not found", http.StatusNotFound)
+		// The kegiatan may have been renamed since the link was shared
+		newSlug, err := h.kegiatanUsecase.ResolveOldSlug(r.Context(), slug)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, isAdmin := GetUserFromContext(r.Context()); !isAdmin && newSlug != "" {
+			newSlug, err = publicSlug(r.Context(), h.kegiatanUsecase, newSlug)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		if newSlug == "" {
+			writeError(w, r, errKegiatanNotFound)
+			return
+		}
+
+		target := strings.TrimSuffix(r.URL.Path, slug) + newSlug
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	if _, isAdmin := GetUserFromContext(r.Context()); !isAdmin && !kegiatan.IsPublic(time.Now()) {
+		writeError(w, r, errKegiatanNotFound)
 		return
 	}
 
@@ -68,15 +150,31 @@ func (h *KegiatanHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// publicSlug returns slug when it belongs to a public kegiatan and "" otherwise, so a redirect
+// from an old slug doesn't reveal where a draft, scheduled or trashed kegiatan now lives.
+func publicSlug(ctx context.Context, kegiatanUsecase usecase.KegiatanUsecase, slug string) (string, error) {
+	kegiatan, err := kegiatanUsecase.GetBySlug(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	if kegiatan == nil || !kegiatan.IsPublic(time.Now()) {
+		return "", nil
+	}
+	return slug, nil
+}
+
 func (h *KegiatanHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var kegiatan entity.Kegiatan
 	if err := json.NewDecoder(r.Body).Decode(&kegiatan); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
+	if !validRequest(w, r, h.validator, &kegiatan) {
+		return
+	}
 	if err := h.kegiatanUsecase.Create(r.Context(), &kegiatan); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -93,22 +191,43 @@ func (h *KegiatanHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var kegiatan entity.Kegiatan
 	if err := json.NewDecoder(r.Body).Decode(&kegiatan); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
 	kegiatan.ID = id
+	if kegiatan.Version, err = ifMatchVersion(r, kegiatan.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	current, err := h.kegiatanUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, &kegiatan, current) {
+		return
+	}
 	if err := h.kegiatanUsecase.Update(r.Context(), &kegiatan); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.kegiatanUsecase.GetByID, id, errKegiatanNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, kegiatan.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -121,12 +240,15 @@ func (h *KegiatanHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.kegiatanUsecase.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errKegiatanNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -136,3 +258,137 @@ func (h *KegiatanHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Kegiatan deleted successfully",
 	})
 }
+
+func (h *KegiatanHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, _, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	revisions, err := h.kegiatanUsecase.GetRevisions(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
+func (h *KegiatanHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, _, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	from, to, err := diffVersions(r)
+	if err != nil {
+		writeError(w, r, errInvalidRevisionRange)
+		return
+	}
+
+	diff, err := h.kegiatanUsecase.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+func (h *KegiatanHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, version, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	existing, err := h.kegiatanUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if existing == nil {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+
+	// The restore is an update: it needs the version the client last saw, or the one just read
+	currentVersion, err := ifMatchVersion(r, existing.Version)
+	if err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	if err := h.kegiatanUsecase.RestoreRevision(r.Context(), id, version, currentVersion); err != nil {
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.kegiatanUsecase.GetByID, id, errKegiatanNotFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, kegiatan.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    kegiatan,
+		"message": "Kegiatan restored to revision " + strconv.Itoa(version),
+	})
+}
+
+// kegiatanPatchFields are the members a PATCH may change. "version" works like If-Match.
+var kegiatanPatchFields = patchFields{
+	"judul":      requiredString,
+	"deskripsi":  requiredString,
+	"cover":      requiredString,
+	"tanggal":    nil,
+	"start_at":   nil,
+	"end_at":     nil,
+	"timezone":   nil,
+	"location":   nil,
+	"status":     nil,
+	"publish_at": nil,
+	"categories": nil,
+	"tags":       nil,
+	"version":    nil,
+}
+
+// Patch applies a JSON merge patch to a kegiatan and returns it as stored afterwards.
+func (h *KegiatanHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Kegiatan]{
+		fields:   kegiatanPatchFields,
+		load:     h.kegiatanUsecase.GetByID,
+		update:   h.kegiatanUsecase.Update,
+		notFound: errKegiatanNotFound,
+		message:  "Kegiatan updated successfully",
+		// Moving only the date (as older clients do) reschedules the whole day
+		prepare: func(patch map[string]interface{}) {
+			if _, ok := patch["tanggal"]; ok {
+				for _, field := range []string{"start_at", "end_at"} {
+					if _, ok := patch[field]; !ok {
+						patch[field] = nil
+					}
+				}
+			}
+		},
+	})
+}
@@ -4,34 +4,53 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 
 	"github.com/gorilla/mux"
 )
 
 type KegiatanPhotoHandler struct {
 	kegiatanPhotoUsecase usecase.KegiatanPhotoUsecase
+	kegiatanUsecase      usecase.KegiatanUsecase
+	validator            *validation.Validator
 }
 
-func NewKegiatanPhotoHandler(kegiatanPhotoUsecase usecase.KegiatanPhotoUsecase) *KegiatanPhotoHandler {
+func NewKegiatanPhotoHandler(kegiatanPhotoUsecase usecase.KegiatanPhotoUsecase, kegiatanUsecase usecase.KegiatanUsecase, validator *validation.Validator) *KegiatanPhotoHandler {
 	return &KegiatanPhotoHandler{
 		kegiatanPhotoUsecase: kegiatanPhotoUsecase,
+		kegiatanUsecase:      kegiatanUsecase,
+		validator:            validator,
 	}
 }
 
+// GetByKegiatanID lists the photos of a kegiatan visitors may see; drafts, scheduled and
+// trashed kegiatan answer 404 like GetByID does.
 func (h *KegiatanPhotoHandler) GetByKegiatanID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	kegiatanID, err := strconv.Atoi(vars["kegiatan_id"])
 	if err != nil {
-		http.Error(w, "Invalid kegiatan ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	kegiatan, err := h.kegiatanUsecase.GetByID(r.Context(), kegiatanID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if kegiatan == nil || !kegiatan.IsPublic(time.Now()) {
+		writeError(w, r, errKegiatanNotFound)
 		return
 	}
 
 	photos, err := h.kegiatanPhotoUsecase.GetByKegiatanID(r.Context(), kegiatanID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -46,7 +65,7 @@ func (h *KegiatanPhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	kegiatanID, err := strconv.Atoi(vars["kegiatan_id"])
 	if err != nil {
-		http.Error(w, "Invalid kegiatan ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
@@ -57,7 +76,7 @@ func (h *KegiatanPhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
@@ -68,9 +87,12 @@ func (h *KegiatanPhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		SortOrder:  req.SortOrder,
 	}
 
+	if !validRequest(w, r, h.validator, photo) {
+		return
+	}
 	err = h.kegiatanPhotoUsecase.Create(r.Context(), photo)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -86,7 +108,7 @@ func (h *KegiatanPhotoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	photoID, err := strconv.Atoi(vars["photo_id"])
 	if err != nil {
-		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
@@ -94,10 +116,11 @@ func (h *KegiatanPhotoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		ImageURL  string `json:"image_url"`
 		Caption   string `json:"caption"`
 		SortOrder int    `json:"sort_order"`
+		Version   int    `json:"version"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
@@ -107,13 +130,34 @@ func (h *KegiatanPhotoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Caption:   req.Caption,
 		SortOrder: req.SortOrder,
 	}
+	if photo.Version, err = ifMatchVersion(r, req.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
 
+	current, err := h.kegiatanPhotoUsecase.GetByID(r.Context(), photoID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errPhotoNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, photo, current) {
+		return
+	}
 	err = h.kegiatanPhotoUsecase.Update(r.Context(), photo)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.kegiatanPhotoUsecase.GetByID, photoID, errPhotoNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, photo.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -126,13 +170,16 @@ func (h *KegiatanPhotoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	photoID, err := strconv.Atoi(vars["photo_id"])
 	if err != nil {
-		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	err = h.kegiatanPhotoUsecase.Delete(r.Context(), photoID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errPhotoNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -152,13 +199,13 @@ func (h *KegiatanPhotoHandler) UpdateSortOrder(w http.ResponseWriter, r *http.Re
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
 	err := h.kegiatanPhotoUsecase.UpdateSortOrder(r.Context(), req.Photos)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -168,3 +215,29 @@ func (h *KegiatanPhotoHandler) UpdateSortOrder(w http.ResponseWriter, r *http.Re
 		"message": "Sort order updated successfully",
 	})
 }
+
+// photoPatchFields are the members a PATCH may change. "version" works like If-Match.
+var photoPatchFields = patchFields{
+	"image_url":  requiredString,
+	"caption":    nil,
+	"sort_order": nil,
+	"version":    nil,
+}
+
+// Patch applies a JSON merge patch to a kegiatan photo and returns it as stored afterwards.
+func (h *KegiatanPhotoHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	photoID, err := strconv.Atoi(vars["photo_id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, photoID, h.validator, patchSpec[entity.KegiatanFoto]{
+		fields:   photoPatchFields,
+		load:     h.kegiatanPhotoUsecase.GetByID,
+		update:   h.kegiatanPhotoUsecase.Update,
+		notFound: errPhotoNotFound,
+		message:  "Photo updated successfully",
+	})
+}
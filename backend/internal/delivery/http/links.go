@@ -0,0 +1,24 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"strconv"
+	"strings"
+)
+
+// kegiatanURL returns the public page of a kegiatan, preferring its slug over the numeric ID.
+func kegiatanURL(siteURL string, k *entity.Kegiatan) string {
+	path := strconv.Itoa(k.ID)
+	if k.Slug != "" {
+		path = k.Slug
+	}
+	return siteURL + "/kegiatan/" + path
+}
+
+// absoluteURL turns a stored path such as /uploads/x.jpg into a full URL on the public site.
+func absoluteURL(siteURL, path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return siteURL + "/" + strings.TrimPrefix(path, "/")
+}
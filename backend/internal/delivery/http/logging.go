@@ -0,0 +1,150 @@
+package http
+
+import (
+	"arshaka-backend/pkg/logging"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength keeps IDs from proxies while stopping clients from stuffing the logs.
+const maxRequestIDLength = 128
+
+type accessEntryKey struct{}
+
+// accessEntry collects what inner handlers learn about a request for its access-log line.
+type accessEntry struct {
+	route string
+	user  string
+}
+
+// RequestLogger gives every request an ID, taken from X-Request-ID when the client or a proxy
+// sent a usable one, and echoes it in the response. The ID is stored in the request context, so
+// every log record of the request carries it. Once the request is done it writes one access-log
+// line.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		entry := &accessEntry{}
+		ctx := logging.WithRequestID(r.Context(), id)
+		ctx = context.WithValue(ctx, accessEntryKey{}, entry)
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", entry.route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes", rec.bytes),
+		}
+		if entry.user != "" {
+			attrs = append(attrs, slog.String("user", entry.user))
+		}
+		slog.LogAttrs(ctx, slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// RecordRoute notes the matched route template, e.g. /api/kegiatan/{id}, for the access log and
+// names the request span after it. It is registered with router.Use because the route is only
+// known once mux has matched it.
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			template, _ := route.GetPathTemplate()
+			if entry, ok := r.Context().Value(accessEntryKey{}).(*accessEntry); ok {
+				entry.route = template
+			}
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + template)
+			span.SetAttributes(semconv.HTTPRoute(template))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Trace starts a span for every request except probes and metric scrapes, continuing the trace of
+// the caller when it sent a traceparent header.
+func Trace(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "HTTP request",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			switch r.URL.Path {
+			case "/healthz", "/readyz", "/metrics":
+				return false
+			}
+			return true
+		}),
+	)
+}
+
+// setAccessUser records the admin that made the request.
+func setAccessUser(ctx context.Context, username string) {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.user = username
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// responseRecorder remembers the status and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
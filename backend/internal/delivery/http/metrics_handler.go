@@ -0,0 +1,57 @@
+package http
+
+import (
+	"arshaka-backend/internal/metrics"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type MetricsHandler struct {
+	metrics *metrics.Metrics
+	handler http.Handler
+	token   string
+}
+
+// NewMetricsHandler serves m for Prometheus. When token is set, scrapes must send it as a bearer
+// token.
+func NewMetricsHandler(m *metrics.Metrics, token string) *MetricsHandler {
+	return &MetricsHandler{
+		metrics: m,
+		handler: m.Handler(),
+		token:   token,
+	}
+}
+
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			writeError(w, r, errAuthorizationRequired)
+			return
+		}
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			writeError(w, r, errInvalidToken)
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+// Instrument counts requests and their latency by route template. It must run inside
+// RequestLogger, which provides the route recorded by RecordRoute.
+func (h *MetricsHandler) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if entry, ok := r.Context().Value(accessEntryKey{}).(*accessEntry); ok && entry.route != "" {
+			route = entry.route
+		}
+		h.metrics.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+	})
+}
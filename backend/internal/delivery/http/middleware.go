@@ -2,8 +2,8 @@ package http
 
 import (
 	"context"
+	"io"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,45 +19,87 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 }
 
-func JWTMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Check if header starts with "Bearer "
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
-			secret := os.Getenv("JWT_SECRET")
-			if secret == "" {
-				secret = "your-super-secret-jwt-key-here"
+// JWTMiddleware only lets requests through that carry a valid token signed with secret.
+func JWTMiddleware(secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				writeError(w, r, errAuthorizationRequired)
+				return
 			}
-			return []byte(secret), nil
+
+			// Check if header starts with "Bearer "
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if tokenString == authHeader {
+				writeError(w, r, errInvalidAuthorizationValue)
+				return
+			}
+
+			// Parse and validate token
+			token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
+				return []byte(secret), nil
+			})
+
+			if err != nil || !token.Valid {
+				writeError(w, r, errInvalidToken)
+				return
+			}
+
+			claims, ok := token.Claims.(*UserClaims)
+			if !ok {
+				writeError(w, r, errInvalidToken)
+				return
+			}
+
+			// Add user info to context
+			setAccessUser(r.Context(), claims.Username)
+			ctx := context.WithValue(r.Context(), UserContextKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
+	}
+}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		claims, ok := token.Claims.(*UserClaims)
-		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-			return
-		}
-
-		// Add user info to context
-		ctx := context.WithValue(r.Context(), UserContextKey, claims)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// LimitBody caps every request body at limit bytes. Handlers that accept larger bodies,
+// such as uploads, raise the cap with limitBody.
+func LimitBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil {
+				r.Body = limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit), raw: r.Body}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitedBody is a body capped by LimitBody that keeps the original, so a handler can
+// replace the cap instead of nesting a larger one inside it.
+type limitedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+// limitBody replaces the request body's cap with limit bytes.
+func limitBody(w http.ResponseWriter, r *http.Request, limit int64) {
+	body := r.Body
+	if capped, ok := body.(limitedBody); ok {
+		body = capped.raw
+	}
+	r.Body = http.MaxBytesReader(w, body, limit)
+}
+
+// SecurityHeaders adds headers to every response before the handler runs, so errors and
+// redirects carry them too.
+func SecurityHeaders(headers map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // Helper function to get user from context
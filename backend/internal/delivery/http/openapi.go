@@ -0,0 +1,366 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/pkg/cache"
+	"arshaka-backend/pkg/openapi"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//go:embed openapi_docs.html
+var openAPIDocsPage []byte
+
+// Request and response bodies that have no entity of their own.
+type (
+	photoSortOrderRequest struct {
+		Photos []photoSortOrder `json:"photos"`
+	}
+	photoSortOrder struct {
+		ID        int `json:"id"`
+		SortOrder int `json:"sort_order"`
+	}
+	uploadedImage struct {
+		URL      string `json:"url"`
+		Filename string `json:"filename"`
+		FileID   int    `json:"file_id"`
+	}
+)
+
+// apiOperation describes one registered route for the OpenAPI document. body and data are
+// either example values whose type becomes the schema, or a ready *openapi.Schema.
+type apiOperation struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	admin   bool
+	query   []string
+	body    interface{}
+	// bodyType is the request media type, application/json when empty
+	bodyType string
+	// data is what the success envelope carries in "data"; nil when it has none
+	data   interface{}
+	status int
+	// plain serves data as the whole body instead of inside the envelope
+	plain bool
+	// produces replaces the JSON envelope for feeds, calendars and pages
+	produces string
+}
+
+var uploadImageForm = &openapi.Schema{
+	Type:     "object",
+	Required: []string{"image"},
+	Properties: map[string]*openapi.Schema{
+		"image":   {Type: "string", Format: "binary"},
+		"context": {Type: "string"},
+	},
+}
+
+var uploadImagesForm = &openapi.Schema{
+	Type:     "object",
+	Required: []string{"images"},
+	Properties: map[string]*openapi.Schema{
+		"images":  {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}},
+		"context": {Type: "string"},
+	},
+}
+
+const mergePatchJSON = "application/merge-patch+json"
+
+// apiOperations lists every route registered in NewRouter. CheckRoutes fails when the two differ.
+var apiOperations = []apiOperation{
+	{method: "GET", path: "/feed.xml", tag: "Feeds", summary: "Atom feed of recently published kegiatan", produces: "application/atom+xml"},
+	{method: "GET", path: "/sitemap.xml", tag: "Feeds", summary: "Sitemap with kegiatan pages and their images", produces: "application/xml"},
+	{method: "GET", path: "/kegiatan/{ref}", tag: "Feeds", summary: "Link preview of a kegiatan by ID or slug", produces: "text/html"},
+
+	{method: "GET", path: "/healthz", tag: "Health", summary: "Liveness: the process is serving requests", data: map[string]string{}, plain: true},
+	{method: "GET", path: "/readyz", tag: "Health", summary: "Readiness: database, uploads directory and migrations, each with latency; 503 when a check fails", data: entity.Readiness{}, plain: true},
+	{method: "GET", path: "/metrics", tag: "Health", summary: "Prometheus metrics; needs Authorization: Bearer <METRICS_TOKEN> when a token is configured", produces: "text/plain"},
+	{method: "GET", path: "/version", tag: "Health", summary: "Git commit, build time and schema version of the running binary", data: entity.BuildInfo{}, plain: true},
+
+	{method: "GET", path: "/api/openapi.json", tag: "Docs", summary: "This OpenAPI document", produces: "application/json"},
+	{method: "GET", path: "/api/docs", tag: "Docs", summary: "API documentation browser", produces: "text/html"},
+
+	{method: "POST", path: "/api/admin/login", tag: "Auth", summary: "Log in as admin", body: entity.LoginRequest{}, data: entity.LoginResponse{}},
+
+	{method: "GET", path: "/api/banners", tag: "Public", summary: "List banners", data: []entity.Banner{}},
+	{method: "GET", path: "/api/kegiatan", tag: "Public", summary: "List published kegiatan", query: []string{"category", "tag"}, data: []entity.Kegiatan{}},
+	{method: "GET", path: "/api/kegiatan/upcoming", tag: "Public", summary: "Upcoming and ongoing kegiatan, soonest first", query: []string{"limit"}, data: []entity.Kegiatan{}},
+	{method: "GET", path: "/api/kegiatan.ics", tag: "Public", summary: "iCalendar feed of published kegiatan", query: []string{"category", "tag"}, produces: "text/calendar"},
+	{method: "GET", path: "/api/kegiatan/{id}.ics", tag: "Public", summary: "A single kegiatan as an .ics file", produces: "text/calendar"},
+	{method: "GET", path: "/api/kegiatan/{id}", tag: "Public", summary: "Get a published kegiatan", data: entity.Kegiatan{}},
+	{method: "GET", path: "/api/kegiatan/slug/{slug}", tag: "Public", summary: "Get a published kegiatan by slug", data: entity.Kegiatan{}},
+	{method: "GET", path: "/api/kegiatan/{kegiatan_id}/photos", tag: "Public", summary: "List the photos of a kegiatan", data: []entity.KegiatanFoto{}},
+	{method: "GET", path: "/api/struktur", tag: "Public", summary: "List struktur members", data: []entity.Struktur{}},
+	{method: "GET", path: "/api/pembina", tag: "Public", summary: "List pembina", data: []entity.Pembina{}},
+	{method: "GET", path: "/api/qrcode/enabled", tag: "Public", summary: "List enabled QR codes", data: []entity.QRCode{}},
+	{method: "GET", path: "/api/categories", tag: "Public", summary: "List categories with their kegiatan count", data: []entity.Category{}},
+	{method: "GET", path: "/api/tags", tag: "Public", summary: "List tags", data: []entity.Tag{}},
+
+	{method: "GET", path: "/api/admin/banners", tag: "Banners", summary: "List banners", admin: true, data: []entity.Banner{}},
+	{method: "GET", path: "/api/admin/banners/{id}", tag: "Banners", summary: "Get a banner", admin: true, data: entity.Banner{}},
+	{method: "POST", path: "/api/admin/banners", tag: "Banners", summary: "Create a banner", admin: true, body: entity.Banner{}, data: entity.Banner{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/banners/{id}", tag: "Banners", summary: "Update a banner", admin: true, body: entity.Banner{}, data: entity.Banner{}},
+	{method: "PATCH", path: "/api/admin/banners/{id}", tag: "Banners", summary: "Patch a banner", admin: true, body: entity.Banner{}, bodyType: mergePatchJSON, data: entity.Banner{}},
+	{method: "DELETE", path: "/api/admin/banners/{id}", tag: "Banners", summary: "Move a banner to the trash", admin: true},
+
+	{method: "GET", path: "/api/admin/kegiatan", tag: "Kegiatan", summary: "List kegiatan including drafts", admin: true, query: []string{"category", "tag"}, data: []entity.Kegiatan{}},
+	{method: "GET", path: "/api/admin/kegiatan/{id}", tag: "Kegiatan", summary: "Get a kegiatan", admin: true, data: entity.Kegiatan{}},
+	{method: "POST", path: "/api/admin/kegiatan", tag: "Kegiatan", summary: "Create a kegiatan", admin: true, body: entity.Kegiatan{}, data: entity.Kegiatan{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/kegiatan/{id}", tag: "Kegiatan", summary: "Update a kegiatan", admin: true, body: entity.Kegiatan{}, data: entity.Kegiatan{}},
+	{method: "PATCH", path: "/api/admin/kegiatan/{id}", tag: "Kegiatan", summary: "Patch a kegiatan", admin: true, body: entity.Kegiatan{}, bodyType: mergePatchJSON, data: entity.Kegiatan{}},
+	{method: "DELETE", path: "/api/admin/kegiatan/{id}", tag: "Kegiatan", summary: "Move a kegiatan to the trash", admin: true},
+	{method: "GET", path: "/api/admin/kegiatan/{id}/revisions", tag: "Kegiatan", summary: "List saved versions, newest first", admin: true, data: []entity.Revision{}},
+	{method: "GET", path: "/api/admin/kegiatan/{id}/revisions/diff", tag: "Kegiatan", summary: "Field changes between two versions", admin: true, query: []string{"from", "to"}, data: entity.RevisionDiff{}},
+	{method: "POST", path: "/api/admin/kegiatan/{id}/revisions/{version}/restore", tag: "Kegiatan", summary: "Roll back to a saved version", admin: true, data: entity.Kegiatan{}},
+
+	{method: "GET", path: "/api/admin/struktur", tag: "Struktur", summary: "List struktur members", admin: true, data: []entity.Struktur{}},
+	{method: "GET", path: "/api/admin/struktur/{id}", tag: "Struktur", summary: "Get a struktur member", admin: true, data: entity.Struktur{}},
+	{method: "POST", path: "/api/admin/struktur", tag: "Struktur", summary: "Create a struktur member", admin: true, body: entity.Struktur{}, data: entity.Struktur{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/struktur/{id}", tag: "Struktur", summary: "Update a struktur member", admin: true, body: entity.Struktur{}, data: entity.Struktur{}},
+	{method: "PATCH", path: "/api/admin/struktur/{id}", tag: "Struktur", summary: "Patch a struktur member", admin: true, body: entity.Struktur{}, bodyType: mergePatchJSON, data: entity.Struktur{}},
+	{method: "DELETE", path: "/api/admin/struktur/{id}", tag: "Struktur", summary: "Move a struktur member to the trash", admin: true},
+	{method: "GET", path: "/api/admin/struktur/{id}/revisions", tag: "Struktur", summary: "List saved versions, newest first", admin: true, data: []entity.Revision{}},
+	{method: "GET", path: "/api/admin/struktur/{id}/revisions/diff", tag: "Struktur", summary: "Field changes between two versions", admin: true, query: []string{"from", "to"}, data: entity.RevisionDiff{}},
+	{method: "POST", path: "/api/admin/struktur/{id}/revisions/{version}/restore", tag: "Struktur", summary: "Roll back to a saved version", admin: true, data: entity.Struktur{}},
+
+	{method: "GET", path: "/api/admin/pembina", tag: "Pembina", summary: "List pembina", admin: true, data: []entity.Pembina{}},
+	{method: "GET", path: "/api/admin/pembina/{id}", tag: "Pembina", summary: "Get a pembina", admin: true, data: entity.Pembina{}},
+	{method: "POST", path: "/api/admin/pembina", tag: "Pembina", summary: "Create a pembina", admin: true, body: entity.Pembina{}, data: entity.Pembina{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/pembina/{id}", tag: "Pembina", summary: "Update a pembina", admin: true, body: entity.Pembina{}, data: entity.Pembina{}},
+	{method: "PATCH", path: "/api/admin/pembina/{id}", tag: "Pembina", summary: "Patch a pembina", admin: true, body: entity.Pembina{}, bodyType: mergePatchJSON, data: entity.Pembina{}},
+	{method: "DELETE", path: "/api/admin/pembina/{id}", tag: "Pembina", summary: "Move a pembina to the trash", admin: true},
+
+	{method: "GET", path: "/api/admin/qrcode", tag: "QR Codes", summary: "List QR codes", admin: true, data: []entity.QRCode{}},
+	{method: "GET", path: "/api/admin/qrcode/{id}", tag: "QR Codes", summary: "Get a QR code", admin: true, data: entity.QRCode{}},
+	{method: "POST", path: "/api/admin/qrcode", tag: "QR Codes", summary: "Create a QR code", admin: true, body: entity.QRCode{}, data: entity.QRCode{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/qrcode/{id}", tag: "QR Codes", summary: "Update a QR code", admin: true, body: entity.QRCode{}, data: entity.QRCode{}},
+	{method: "PATCH", path: "/api/admin/qrcode/{id}", tag: "QR Codes", summary: "Patch a QR code", admin: true, body: entity.QRCode{}, bodyType: mergePatchJSON, data: entity.QRCode{}},
+	{method: "DELETE", path: "/api/admin/qrcode/{id}", tag: "QR Codes", summary: "Move a QR code to the trash", admin: true},
+	{method: "PUT", path: "/api/admin/qrcode/{id}/toggle", tag: "QR Codes", summary: "Enable or disable a QR code", admin: true},
+
+	{method: "GET", path: "/api/admin/categories", tag: "Categories", summary: "List categories", admin: true, data: []entity.Category{}},
+	{method: "GET", path: "/api/admin/categories/{id}", tag: "Categories", summary: "Get a category", admin: true, data: entity.Category{}},
+	{method: "POST", path: "/api/admin/categories", tag: "Categories", summary: "Create a category", admin: true, body: entity.Category{}, data: entity.Category{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/categories/{id}", tag: "Categories", summary: "Update a category", admin: true, body: entity.Category{}, data: entity.Category{}},
+	{method: "PATCH", path: "/api/admin/categories/{id}", tag: "Categories", summary: "Patch a category", admin: true, body: entity.Category{}, bodyType: mergePatchJSON, data: entity.Category{}},
+	{method: "DELETE", path: "/api/admin/categories/{id}", tag: "Categories", summary: "Delete a category", admin: true},
+
+	{method: "GET", path: "/api/admin/tags", tag: "Tags", summary: "List tags", admin: true, data: []entity.Tag{}},
+	{method: "GET", path: "/api/admin/tags/{id}", tag: "Tags", summary: "Get a tag", admin: true, data: entity.Tag{}},
+	{method: "POST", path: "/api/admin/tags", tag: "Tags", summary: "Create a tag", admin: true, body: entity.Tag{}, data: entity.Tag{}, status: http.StatusCreated},
+	{method: "PUT", path: "/api/admin/tags/{id}", tag: "Tags", summary: "Update a tag", admin: true, body: entity.Tag{}, data: entity.Tag{}},
+	{method: "PATCH", path: "/api/admin/tags/{id}", tag: "Tags", summary: "Patch a tag", admin: true, body: entity.Tag{}, bodyType: mergePatchJSON, data: entity.Tag{}},
+	{method: "DELETE", path: "/api/admin/tags/{id}", tag: "Tags", summary: "Delete a tag", admin: true},
+
+	{method: "POST", path: "/api/admin/kegiatan/{kegiatan_id}/photos", tag: "Photos", summary: "Add a photo to a kegiatan", admin: true, body: entity.KegiatanFoto{}, data: entity.KegiatanFoto{}},
+	{method: "PUT", path: "/api/admin/photos/{photo_id}", tag: "Photos", summary: "Update a photo", admin: true, body: entity.KegiatanFoto{}, data: entity.KegiatanFoto{}},
+	{method: "PATCH", path: "/api/admin/photos/{photo_id}", tag: "Photos", summary: "Patch a photo", admin: true, body: entity.KegiatanFoto{}, bodyType: mergePatchJSON, data: entity.KegiatanFoto{}},
+	{method: "DELETE", path: "/api/admin/photos/{photo_id}", tag: "Photos", summary: "Move a photo to the trash", admin: true},
+	{method: "PUT", path: "/api/admin/photos/sort-order", tag: "Photos", summary: "Reorder photos", admin: true, body: photoSortOrderRequest{}},
+
+	{method: "GET", path: "/api/admin/cache", tag: "Cache", summary: "Hits, misses, invalidations and entries of the public response cache by group", admin: true, data: map[string]cache.Stats{}},
+
+	{method: "GET", path: "/api/admin/trash", tag: "Trash", summary: "List deleted items", admin: true, data: []entity.TrashItem{}},
+	{method: "POST", path: "/api/admin/trash/{type}/{id}/restore", tag: "Trash", summary: "Restore a deleted item", admin: true},
+	{method: "DELETE", path: "/api/admin/trash/{type}/{id}", tag: "Trash", summary: "Permanently delete an item", admin: true},
+
+	{method: "POST", path: "/api/admin/upload/image", tag: "Uploads", summary: "Upload an image", admin: true, body: uploadImageForm, bodyType: "multipart/form-data", data: uploadedImage{}},
+	{method: "POST", path: "/api/admin/upload/images", tag: "Uploads", summary: "Upload several images", admin: true, body: uploadImagesForm, bodyType: "multipart/form-data", data: []uploadedImage{}},
+}
+
+// pathParam matches the variables of a path template; the optional pattern is mux-only.
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// integerParams are path and query parameters that only take numbers.
+var integerParams = map[string]bool{
+	"id": true, "kegiatan_id": true, "photo_id": true, "version": true,
+	"limit": true, "from": true, "to": true,
+}
+
+// buildOpenAPI assembles the document from apiOperations.
+func buildOpenAPI() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Arshaka Bimantara API",
+		Description: "Public content and admin endpoints of the Arshaka Bimantara website.",
+		Version:     "1.0.0",
+	})
+	doc.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	doc.Components.Schemas["Error"] = &openapi.Schema{
+		Type:     "object",
+		Required: []string{"success", "error"},
+		Properties: map[string]*openapi.Schema{
+			"success": {Type: "boolean"},
+			"error": {
+				Type:     "object",
+				Required: []string{"code", "message"},
+				Properties: map[string]*openapi.Schema{
+					"code":    {Type: "string"},
+					"message": {Type: "string"},
+					"fields":  {Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"data": {},
+		},
+	}
+	errorResponse := openapi.Response{
+		Description: "Error envelope",
+		Content: map[string]openapi.MediaType{
+			"application/json": {Schema: &openapi.Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+
+	schemaOf := func(v interface{}) *openapi.Schema {
+		if s, ok := v.(*openapi.Schema); ok {
+			return s
+		}
+		return doc.SchemaOf(v)
+	}
+
+	for _, o := range apiOperations {
+		op := &openapi.Operation{
+			Summary:   o.summary,
+			Tags:      []string{o.tag},
+			Responses: map[string]openapi.Response{},
+		}
+
+		for _, m := range pathParam.FindAllStringSubmatch(o.path, -1) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: m[1], In: "path", Required: true, Schema: paramSchema(m[1])})
+		}
+		for _, name := range o.query {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: "query", Schema: paramSchema(name)})
+		}
+
+		if o.body != nil {
+			bodyType := o.bodyType
+			if bodyType == "" {
+				bodyType = "application/json"
+			}
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{bodyType: {Schema: schemaOf(o.body)}},
+			}
+		}
+
+		status := o.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openapi.Response{Description: http.StatusText(status)}
+		if o.produces != "" {
+			success.Content = map[string]openapi.MediaType{o.produces: {Schema: &openapi.Schema{Type: "string"}}}
+		} else if o.plain {
+			success.Content = map[string]openapi.MediaType{"application/json": {Schema: schemaOf(o.data)}}
+		} else {
+			envelope := &openapi.Schema{
+				Type:     "object",
+				Required: []string{"success"},
+				Properties: map[string]*openapi.Schema{
+					"success": {Type: "boolean"},
+					"message": {Type: "string"},
+				},
+			}
+			if o.data != nil {
+				envelope.Properties["data"] = schemaOf(o.data)
+			}
+			success.Content = map[string]openapi.MediaType{"application/json": {Schema: envelope}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = errorResponse
+
+		if o.admin {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+		doc.Add(o.method, o.path, op)
+	}
+	return doc
+}
+
+func paramSchema(name string) *openapi.Schema {
+	if integerParams[name] {
+		return &openapi.Schema{Type: "integer"}
+	}
+	return &openapi.Schema{Type: "string"}
+}
+
+type OpenAPIHandler struct {
+	doc  *openapi.Document
+	spec []byte
+}
+
+func NewOpenAPIHandler() *OpenAPIHandler {
+	doc := buildOpenAPI()
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		// The document only holds plain structs and maps, so this cannot happen at runtime
+		panic(err)
+	}
+	return &OpenAPIHandler{doc: doc, spec: spec}
+}
+
+// Spec serves the OpenAPI document.
+func (h *OpenAPIHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+// Docs serves a page that renders the document for people.
+func (h *OpenAPIHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(openAPIDocsPage)
+}
+
+// CheckRoutes compares the routes registered on router with the document and reports every
+// route that is missing from either side, so the spec cannot silently fall behind.
+func (h *OpenAPIHandler) CheckRoutes(router *mux.Router) error {
+	registered := map[string]bool{}
+	var undocumented []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Prefixes and static file servers are not API operations
+			return nil
+		}
+		path = pathParam.ReplaceAllString(path, "{$1}")
+		for _, method := range methods {
+			registered[method+" "+path] = true
+			if !h.doc.Has(method, path) {
+				undocumented = append(undocumented, method+" "+path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var unregistered []string
+	for _, o := range apiOperations {
+		if !registered[o.method+" "+o.path] {
+			unregistered = append(unregistered, o.method+" "+o.path)
+		}
+	}
+
+	if len(undocumented) == 0 && len(unregistered) == 0 {
+		return nil
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+	return fmt.Errorf("openapi: routes missing from the spec: [%s]; spec operations without a route: [%s]",
+		strings.Join(undocumented, ", "), strings.Join(unregistered, ", "))
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"arshaka-backend/pkg/cache"
+	"arshaka-backend/pkg/ratelimit"
+	"testing"
+)
+
+// TestRoutesMatchOpenAPI fails when a route is registered without an apiOperations entry, or an
+// entry describes a route that no longer exists.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	openAPI := NewOpenAPIHandler()
+	router := NewRouter(Routes{
+		Health:        &HealthHandler{},
+		Metrics:       &MetricsHandler{},
+		Feed:          &FeedHandler{},
+		Preview:       &PreviewHandler{},
+		OpenAPI:       openAPI,
+		Auth:          &AuthHandler{},
+		Banner:        &BannerHandler{},
+		Kegiatan:      &KegiatanHandler{},
+		KegiatanPhoto: &KegiatanPhotoHandler{},
+		Calendar:      &CalendarHandler{},
+		Struktur:      &StrukturHandler{},
+		Pembina:       &PembinaHandler{},
+		QRCode:        &QRCodeHandler{},
+		Category:      &CategoryHandler{},
+		Tag:           &TagHandler{},
+		Trash:         &TrashHandler{},
+		Upload:        &UploadHandler{},
+		Cache:         NewCacheHandler(cache.New(1), 0, 0),
+		RateLimiter:   NewRateLimiter(ratelimit.NewMemoryStore(), nil),
+		PublicLimit:   ratelimit.Limit{PerMinute: 1, Burst: 1},
+		LoginLimit:    ratelimit.Limit{PerMinute: 1, Burst: 1},
+		AdminLimit:    ratelimit.Limit{PerMinute: 1, Burst: 1},
+		JWTSecret:     "test",
+		UploadsDir:    t.TempDir(),
+	})
+
+	if err := openAPI.CheckRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/internal/validation"
+	"arshaka-backend/pkg/mergepatch"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// PATCH endpoints accept a JSON Merge Patch (RFC 7396): only the members present in the body
+// change, null clears a field, and everything else keeps its stored value.
+
+// patchFields lists the members a PATCH may touch. The check, when set, returns the code of
+// what is wrong with the value, or "" when it is acceptable.
+type patchFields map[string]func(value interface{}) string
+
+// requiredString rejects null and blank strings for fields that must always have a value.
+func requiredString(value interface{}) string {
+	s, ok := value.(string)
+	if !ok && value != nil {
+		return "invalid_type"
+	}
+	if strings.TrimSpace(s) == "" {
+		return "required"
+	}
+	return ""
+}
+
+// readMergePatch decodes the request body as a merge patch and checks every member against
+// fields. It answers the request itself and returns false when the patch is unusable.
+func readMergePatch(w http.ResponseWriter, r *http.Request, fields patchFields) (map[string]interface{}, bool) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			writeError(w, r, errUnsupportedMediaType)
+			return nil, false
+		}
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		writeBodyError(w, r, err, errInvalidMergePatch)
+		return nil, false
+	}
+
+	invalid := make(map[string]string)
+	for field, value := range patch {
+		check, allowed := fields[field]
+		if !allowed {
+			invalid[field] = "read_only"
+			continue
+		}
+		if check != nil {
+			if code := check(value); code != "" {
+				invalid[field] = code
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		writeError(w, r, apperror.ErrInvalidInput.WithFields(invalid))
+		return nil, false
+	}
+
+	return patch, true
+}
+
+// applyMergePatch applies patch to the JSON form of current and decodes the result into dst,
+// which must point to a zero value so cleared members end up empty. Type mismatches are
+// answered as field errors and reported by returning false.
+func applyMergePatch(w http.ResponseWriter, r *http.Request, current interface{}, patch map[string]interface{}, dst interface{}) bool {
+	data, err := json.Marshal(current)
+	if err != nil {
+		writeError(w, r, err)
+		return false
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		writeError(w, r, err)
+		return false
+	}
+
+	merged, err := json.Marshal(mergepatch.Apply(document, patch))
+	if err != nil {
+		writeError(w, r, err)
+		return false
+	}
+
+	if err := json.Unmarshal(merged, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			writeError(w, r, apperror.ErrInvalidInput.WithFields(map[string]string{typeErr.Field: "invalid_type"}))
+			return false
+		}
+		writeError(w, r, errInvalidMergePatch)
+		return false
+	}
+	return true
+}
+
+// patchSpec describes how the PATCH of one admin resource loads, stores and reports it.
+type patchSpec[T any] struct {
+	fields   patchFields
+	load     func(ctx context.Context, id int) (*T, error)
+	update   func(ctx context.Context, v *T) error
+	notFound error
+	message  string
+	// prepare, when set, adjusts the patch before it is applied
+	prepare func(patch map[string]interface{})
+}
+
+// patchEntity applies a merge patch to the row with the given id: it loads the row, merges the
+// patch into it, checks If-Match and the validate tags, saves it and answers with the row as it
+// is stored afterwards.
+func patchEntity[T any](w http.ResponseWriter, r *http.Request, id int, validator *validation.Validator, spec patchSpec[T]) {
+	patch, ok := readMergePatch(w, r, spec.fields)
+	if !ok {
+		return
+	}
+	if spec.prepare != nil {
+		spec.prepare(patch)
+	}
+
+	current, err := spec.load(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, spec.notFound)
+		return
+	}
+
+	var v T
+	if !applyMergePatch(w, r, current, patch, &v) {
+		return
+	}
+
+	row := reflect.ValueOf(&v).Elem()
+	row.FieldByName("ID").SetInt(int64(id))
+	version, err := ifMatchVersion(r, int(row.FieldByName("Version").Int()))
+	if err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	row.FieldByName("Version").SetInt(int64(version))
+
+	if !validUpdate(w, r, validator, &v, current) {
+		return
+	}
+	if err := spec.update(r.Context(), &v); err != nil {
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, spec.load, id, spec.notFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	updated, err := spec.load(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if updated == nil {
+		writeError(w, r, spec.notFound)
+		return
+	}
+
+	setETag(w, rowVersion(updated))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    updated,
+		"message": spec.message,
+	})
+}
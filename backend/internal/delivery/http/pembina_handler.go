@@ -4,8 +4,8 @@ import (
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -14,18 +14,20 @@ import (
 
 type PembinaHandler struct {
 	pembinaUsecase usecase.PembinaUsecase
+	validator      *validation.Validator
 }
 
-func NewPembinaHandler(pembinaUsecase usecase.PembinaUsecase) *PembinaHandler {
+func NewPembinaHandler(pembinaUsecase usecase.PembinaUsecase, validator *validation.Validator) *PembinaHandler {
 	return &PembinaHandler{
 		pembinaUsecase: pembinaUsecase,
+		validator:      validator,
 	}
 }
 
 func (h *PembinaHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	pembina, err := h.pembinaUsecase.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -40,21 +42,22 @@ func (h *PembinaHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	pembina, err := h.pembinaUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if pembina == nil {
-		http.Error(w, "Pembina not found", http.StatusNotFound)
+		writeError(w, r, errPembinaNotFound)
 		return
 	}
 
+	setETag(w, pembina.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -65,16 +68,19 @@ func (h *PembinaHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *PembinaHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var pembina entity.Pembina
 	if err := json.NewDecoder(r.Body).Decode(&pembina); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
+	if !validRequest(w, r, h.validator, &pembina) {
+		return
+	}
 	if err := h.pembinaUsecase.Create(r.Context(), &pembina); err != nil {
 		if err == repository.ErrMaxPembinaReached {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -91,25 +97,43 @@ func (h *PembinaHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var pembina entity.Pembina
 	if err := json.NewDecoder(r.Body).Decode(&pembina); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
-	// Debug log
-	fmt.Printf("Update handler received data: ID=%d, Nama=%s, NIP=%s\n", id, pembina.Nama, pembina.NIP)
-
 	pembina.ID = id
+	if pembina.Version, err = ifMatchVersion(r, pembina.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	current, err := h.pembinaUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errPembinaNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, &pembina, current) {
+		return
+	}
 	if err := h.pembinaUsecase.Update(r.Context(), &pembina); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.pembinaUsecase.GetByID, id, errPembinaNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, pembina.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -122,12 +146,15 @@ func (h *PembinaHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.pembinaUsecase.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errPembinaNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -137,3 +164,30 @@ func (h *PembinaHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Pembina deleted successfully",
 	})
 }
+
+// pembinaPatchFields are the members a PATCH may change. "version" works like If-Match.
+var pembinaPatchFields = patchFields{
+	"nama":     requiredString,
+	"jabatan":  requiredString,
+	"nip":      nil,
+	"foto_url": nil,
+	"version":  nil,
+}
+
+// Patch applies a JSON merge patch to a pembina and returns it as stored afterwards.
+func (h *PembinaHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Pembina]{
+		fields:   pembinaPatchFields,
+		load:     h.pembinaUsecase.GetByID,
+		update:   h.pembinaUsecase.Update,
+		notFound: errPembinaNotFound,
+		message:  "Pembina updated successfully",
+	})
+}
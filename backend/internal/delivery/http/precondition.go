@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Admin GETs expose the row version as a strong ETag. Clients send it back in If-Match (or as
+// "version" in the body) and an update that lost the race is answered with 412 and the row as it
+// is stored now, so the admin can merge their changes instead of silently overwriting.
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// unmatchedVersion is a version no row has, so an update pinned to it never applies.
+const unmatchedVersion = -1
+
+// ifMatchVersion returns the version named by the If-Match header, falling back to the version
+// sent in the body. "*" and a missing header with no body version both yield 0, which skips the check.
+// A list of several tags cannot pin the update to one version, so it yields unmatchedVersion and the
+// client gets the current state back to retry against.
+func ifMatchVersion(r *http.Request, bodyVersion int) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return bodyVersion, nil
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	version, mixed := 0, false
+	for i, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		v, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if err != nil {
+			return 0, err
+		}
+		mixed = mixed || (i > 0 && v != version)
+		version = v
+	}
+	if mixed {
+		return unmatchedVersion, nil
+	}
+	return version, nil
+}
+
+// writePreconditionFailed answers an update made against a stale version with the current state.
+func writePreconditionFailed(w http.ResponseWriter, r *http.Request, current interface{}, version int) {
+	setETag(w, version)
+	writeErrorBody(w, r, errVersionConflict, current)
+}
+
+// writeStale answers an update that matched no row: notFound when load finds nothing under id,
+// otherwise 412 with the row as it is stored now.
+func writeStale[T any](w http.ResponseWriter, r *http.Request, load func(context.Context, int) (*T, error), id int, notFound error) {
+	current, err := load(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, notFound)
+		return
+	}
+
+	writePreconditionFailed(w, r, current, rowVersion(current))
+}
+
+// rowVersion reads the Version field every versioned entity has.
+func rowVersion(v interface{}) int {
+	return int(reflect.Indirect(reflect.ValueOf(v)).FieldByName("Version").Int())
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/usecase"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const previewDescriptionLength = 200
+
+// appParam marks a request for the frontend itself. The proxy only hands kegiatan pages to the
+// backend for crawlers, and leaves those carrying appParam to the frontend.
+const appParam = "app"
+
+// linkPreviewBots matches crawlers that build link previews or index pages without running JavaScript.
+var linkPreviewBots = regexp.MustCompile(`(?i)facebookexternalhit|facebot|whatsapp|twitterbot|telegrambot|slackbot|linkedinbot|discordbot|instagram|pinterest|skypeuripreview|googlebot|bingbot|yandex|duckduckbot|applebot|embedly`)
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} | Arshaka Bimantara</title>
+<meta name="description" content="{{.Description}}">
+<link rel="canonical" href="{{.URL}}">
+<meta property="og:site_name" content="Arshaka Bimantara">
+<meta property="og:type" content="article">
+<meta property="og:locale" content="id_ID">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+{{- if .Image}}
+<meta property="og:image" content="{{.Image}}">
+<meta property="og:image:alt" content="{{.Title}}">
+{{- end}}
+<meta name="twitter:card" content="{{if .Image}}summary_large_image{{else}}summary{{end}}">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+{{- if .Image}}
+<meta name="twitter:image" content="{{.Image}}">
+{{- end}}
+<script type="application/ld+json">{{.JSONLD}}</script>
+<script>location.replace({{.AppURL}})</script>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{- if .Image}}
+<img src="{{.Image}}" alt="{{.Title}}">
+{{- end}}
+<p>{{.Description}}</p>
+<p><a href="{{.URL}}">Lihat kegiatan</a></p>
+</main>
+</body>
+</html>
+`))
+
+type previewPage struct {
+	Title       string
+	Description string
+	URL         string
+	// AppURL is where browsers mistaken for crawlers, such as in-app browsers, are sent on to;
+	// crawlers do not run the script that does it
+	AppURL string
+	Image  string
+	JSONLD template.JS
+}
+
+// PreviewHandler serves crawler-facing HTML for pages of the client-side rendered frontend,
+// so shared links show a title, description and cover image.
+type PreviewHandler struct {
+	kegiatanUsecase usecase.KegiatanUsecase
+	siteURL         string
+}
+
+func NewPreviewHandler(kegiatanUsecase usecase.KegiatanUsecase, siteURL string) *PreviewHandler {
+	return &PreviewHandler{
+		kegiatanUsecase: kegiatanUsecase,
+		siteURL:         strings.TrimSuffix(siteURL, "/"),
+	}
+}
+
+// Kegiatan renders the preview for /kegiatan/{ref}, where ref is either the ID or the slug.
+// Browsers are sent on to the frontend instead.
+func (h *PreviewHandler) Kegiatan(w http.ResponseWriter, r *http.Request) {
+	if !linkPreviewBots.MatchString(r.UserAgent()) && !h.isSiteHost(r) {
+		http.Redirect(w, r, h.siteURL+r.URL.RequestURI(), http.StatusFound)
+		return
+	}
+
+	ref := mux.Vars(r)["ref"]
+
+	var kegiatan *entity.Kegiatan
+	var err error
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		kegiatan, err = h.kegiatanUsecase.GetByID(r.Context(), id)
+	} else {
+		kegiatan, err = h.kegiatanUsecase.GetBySlug(r.Context(), ref)
+		if err == nil && kegiatan == nil {
+			newSlug, resolveErr := h.kegiatanUsecase.ResolveOldSlug(r.Context(), ref)
+			if resolveErr == nil && newSlug != "" {
+				newSlug, resolveErr = publicSlug(r.Context(), h.kegiatanUsecase, newSlug)
+			}
+			if resolveErr == nil && newSlug != "" {
+				http.Redirect(w, r, "/kegiatan/"+newSlug, http.StatusMovedPermanently)
+				return
+			}
+			err = resolveErr
+		}
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if kegiatan == nil || !kegiatan.IsPublic(time.Now()) {
+		writeError(w, r, errKegiatanNotFound)
+		return
+	}
+
+	page := previewPage{
+		Title:       kegiatan.Judul,
+		Description: summarize(kegiatan.Deskripsi, previewDescriptionLength),
+		URL:         kegiatanURL(h.siteURL, kegiatan),
+		Image:       absoluteURL(h.siteURL, kegiatan.Cover),
+	}
+	page.AppURL = page.URL + "?" + appParam + "=1"
+
+	jsonLD, err := json.Marshal(h.eventSchema(kegiatan, page))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	page.JSONLD = template.JS(jsonLD)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := previewTemplate.Execute(w, page); err != nil {
+		slog.ErrorContext(r.Context(), "failed to render kegiatan preview", "error", err)
+	}
+}
+
+// eventSchema describes the kegiatan as a schema.org Event for search engines.
+func (h *PreviewHandler) eventSchema(k *entity.Kegiatan, page previewPage) map[string]interface{} {
+	place := map[string]interface{}{
+		"@type": "Place",
+		"name":  k.Location.Name,
+	}
+	if place["name"] == "" {
+		place["name"] = "Arshaka Bimantara"
+	}
+	if k.Location.Address != "" {
+		place["address"] = k.Location.Address
+	}
+	if k.Location.Latitude != nil && k.Location.Longitude != nil {
+		place["geo"] = map[string]interface{}{
+			"@type":     "GeoCoordinates",
+			"latitude":  *k.Location.Latitude,
+			"longitude": *k.Location.Longitude,
+		}
+	}
+
+	var images []string
+	if page.Image != "" {
+		images = append(images, page.Image)
+	}
+	for _, foto := range k.Fotos {
+		images = append(images, absoluteURL(h.siteURL, foto.ImageURL))
+	}
+
+	schema := map[string]interface{}{
+		"@context":            "https://schema.org",
+		"@type":               "Event",
+		"name":                k.Judul,
+		"description":         page.Description,
+		"url":                 page.URL,
+		"startDate":           k.StartAt.Format(time.RFC3339),
+		"endDate":             k.EndAt.Format(time.RFC3339),
+		"eventStatus":         "https://schema.org/EventScheduled",
+		"eventAttendanceMode": "https://schema.org/OfflineEventAttendanceMode",
+		"location":            place,
+		"organizer": map[string]interface{}{
+			"@type": "Organization",
+			"name":  "Arshaka Bimantara",
+			"url":   h.siteURL + "/",
+		},
+	}
+	if len(images) > 0 {
+		schema["image"] = images
+	}
+
+	return schema
+}
+
+// isSiteHost reports whether the request already arrived on the public site's host, in which
+// case redirecting browsers to the site would loop back here.
+func (h *PreviewHandler) isSiteHost(r *http.Request) bool {
+	u, err := url.Parse(h.siteURL)
+	if err != nil {
+		return false
+	}
+
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.EqualFold(u.Hostname(), host)
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -12,18 +14,20 @@ import (
 
 type QRCodeHandler struct {
 	qrcodeUsecase usecase.QRCodeUsecase
+	validator     *validation.Validator
 }
 
-func NewQRCodeHandler(qrcodeUsecase usecase.QRCodeUsecase) *QRCodeHandler {
+func NewQRCodeHandler(qrcodeUsecase usecase.QRCodeUsecase, validator *validation.Validator) *QRCodeHandler {
 	return &QRCodeHandler{
 		qrcodeUsecase: qrcodeUsecase,
+		validator:     validator,
 	}
 }
 
 func (h *QRCodeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	qrcodes, err := h.qrcodeUsecase.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -37,7 +41,7 @@ func (h *QRCodeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 func (h *QRCodeHandler) GetEnabled(w http.ResponseWriter, r *http.Request) {
 	qrcodes, err := h.qrcodeUsecase.GetEnabled(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -57,21 +61,22 @@ func (h *QRCodeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	qrcode, err := h.qrcodeUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if qrcode == nil {
-		http.Error(w, "QR Code not found", http.StatusNotFound)
+		writeError(w, r, errQRCodeNotFound)
 		return
 	}
 
+	setETag(w, qrcode.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -82,12 +87,15 @@ func (h *QRCodeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *QRCodeHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var qrcode entity.QRCode
 	if err := json.NewDecoder(r.Body).Decode(&qrcode); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
+	if !validRequest(w, r, h.validator, &qrcode) {
+		return
+	}
 	if err := h.qrcodeUsecase.Create(r.Context(), &qrcode); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -104,22 +112,43 @@ func (h *QRCodeHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var qrcode entity.QRCode
 	if err := json.NewDecoder(r.Body).Decode(&qrcode); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
 	qrcode.ID = id
+	if qrcode.Version, err = ifMatchVersion(r, qrcode.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	current, err := h.qrcodeUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errQRCodeNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, &qrcode, current) {
+		return
+	}
 	if err := h.qrcodeUsecase.Update(r.Context(), &qrcode); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.qrcodeUsecase.GetByID, id, errQRCodeNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, qrcode.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -132,12 +161,15 @@ func (h *QRCodeHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.qrcodeUsecase.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errQRCodeNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -152,12 +184,16 @@ func (h *QRCodeHandler) ToggleEnable(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.qrcodeUsecase.ToggleEnable(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeError(w, r, errQRCodeNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -167,3 +203,29 @@ func (h *QRCodeHandler) ToggleEnable(w http.ResponseWriter, r *http.Request) {
 		"message": "QR Code status toggled successfully",
 	})
 }
+
+// qrcodePatchFields are the members a PATCH may change. "version" works like If-Match.
+var qrcodePatchFields = patchFields{
+	"image_url":  requiredString,
+	"keterangan": nil,
+	"enable":     nil,
+	"version":    nil,
+}
+
+// Patch applies a JSON merge patch to a QR code and returns it as stored afterwards.
+func (h *QRCodeHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.QRCode]{
+		fields:   qrcodePatchFields,
+		load:     h.qrcodeUsecase.GetByID,
+		update:   h.qrcodeUsecase.Update,
+		notFound: errQRCodeNotFound,
+		message:  "QR Code updated successfully",
+	})
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"arshaka-backend/pkg/ratelimit"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RateLimiter struct {
+	store          ratelimit.Store
+	trustedProxies []netip.Prefix
+}
+
+// NewRateLimiter keeps the buckets of every client in store. X-Forwarded-For is only believed
+// when the request comes from one of trustedProxies; otherwise any client could pick its own
+// address and never run out of tokens.
+func NewRateLimiter(store ratelimit.Store, trustedProxies []netip.Prefix) *RateLimiter {
+	return &RateLimiter{
+		store:          store,
+		trustedProxies: trustedProxies,
+	}
+}
+
+// Limit gives each client a bucket of its own for the routes of group. Every response carries
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset; a request that finds the bucket
+// empty is answered with 429 and Retry-After. A limit of 0 per minute lets everything through.
+func (l *RateLimiter) Limit(group string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit.PerMinute <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.store.Take(r.Context(), group+":"+l.clientIP(r), limit)
+			if err != nil {
+				// An unreachable store must not take the site down with it
+				slog.WarnContext(r.Context(), "rate limit check failed", "group", group, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", seconds(result.Reset))
+			if !result.Allowed {
+				header.Set("Retry-After", seconds(result.RetryAfter))
+				writeError(w, r, errTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP is the address the request came from. Behind trusted proxies it is the last
+// X-Forwarded-For entry not added by one of them.
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	addr = addr.Unmap()
+
+	if !l.trusted(addr) {
+		return addr.String()
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !l.trusted(addr) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+func (l *RateLimiter) trusted(addr netip.Addr) bool {
+	for _, prefix := range l.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// seconds rounds d up to whole seconds, as the headers count them.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
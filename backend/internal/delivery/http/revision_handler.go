@@ -0,0 +1,48 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// revisionParams reads the entity ID and revision version from the route.
+func revisionParams(r *http.Request) (id, version int, err error) {
+	vars := mux.Vars(r)
+	if id, err = strconv.Atoi(vars["id"]); err != nil {
+		return 0, 0, err
+	}
+	if v, ok := vars["version"]; ok {
+		if version, err = strconv.Atoi(v); err != nil {
+			return 0, 0, err
+		}
+	}
+	return id, version, nil
+}
+
+// diffVersions reads the ?from= and ?to= versions of a revision diff request.
+func diffVersions(r *http.Request) (from, to int, err error) {
+	query := r.URL.Query()
+	if from, err = strconv.Atoi(query.Get("from")); err != nil {
+		return 0, 0, err
+	}
+	if to, err = strconv.Atoi(query.Get("to")); err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+func writeRevisions(w http.ResponseWriter, revisions []entity.Revision) {
+	if revisions == nil {
+		revisions = []entity.Revision{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    revisions,
+	})
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"arshaka-backend/internal/usecase"
+	"arshaka-backend/pkg/ratelimit"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Routes is everything NewRouter wires together.
+type Routes struct {
+	Health        *HealthHandler
+	Metrics       *MetricsHandler
+	Feed          *FeedHandler
+	Preview       *PreviewHandler
+	OpenAPI       *OpenAPIHandler
+	Auth          *AuthHandler
+	Banner        *BannerHandler
+	Kegiatan      *KegiatanHandler
+	KegiatanPhoto *KegiatanPhotoHandler
+	Calendar      *CalendarHandler
+	Struktur      *StrukturHandler
+	Pembina       *PembinaHandler
+	QRCode        *QRCodeHandler
+	Category      *CategoryHandler
+	Tag           *TagHandler
+	Trash         *TrashHandler
+	Upload        *UploadHandler
+	Cache         *CacheHandler
+	RateLimiter   *RateLimiter
+
+	// PublicLimit, LoginLimit and AdminLimit are the rate limits of the route groups
+	PublicLimit ratelimit.Limit
+	LoginLimit  ratelimit.Limit
+	AdminLimit  ratelimit.Limit
+	JWTSecret   string
+	UploadsDir  string
+}
+
+// NewRouter registers every route of the backend. Each one must be described in apiOperations,
+// which openapi_test.go checks.
+func NewRouter(rt Routes) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(RecordRoute)
+
+	// Probes and build information
+	router.HandleFunc("/healthz", rt.Health.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", rt.Health.Readyz).Methods("GET")
+	router.HandleFunc("/version", rt.Health.Version).Methods("GET")
+	router.HandleFunc("/metrics", rt.Metrics.Metrics).Methods("GET")
+
+	// Pages for crawlers and the public API share one rate limit per client
+	publicLimit := rt.RateLimiter.Limit("public", rt.PublicLimit)
+	pages := router.NewRoute().Subrouter()
+	pages.Use(publicLimit)
+
+	// Feeds for search engines and news aggregators
+	pages.HandleFunc("/feed.xml", rt.Feed.Atom).Methods("GET")
+	pages.HandleFunc("/sitemap.xml", rt.Feed.Sitemap).Methods("GET")
+
+	// Link previews for shared kegiatan pages
+	pages.HandleFunc("/kegiatan/{ref}", rt.Preview.Kegiatan).Methods("GET")
+
+	// API routes
+	api := router.PathPrefix("/api").Subrouter()
+	publicAPI := api.NewRoute().Subrouter()
+	publicAPI.Use(publicLimit)
+
+	// API documentation
+	publicAPI.HandleFunc("/openapi.json", rt.OpenAPI.Spec).Methods("GET")
+	publicAPI.HandleFunc("/docs", rt.OpenAPI.Docs).Methods("GET")
+
+	// Auth routes (public), with a strict limit against password guessing
+	api.Handle("/admin/login", rt.RateLimiter.Limit("login", rt.LoginLimit)(http.HandlerFunc(rt.Auth.Login))).Methods("POST")
+
+	// Public routes
+	publicAPI.HandleFunc("/banners", rt.Cache.Public(usecase.CacheGroupBanners, rt.Banner.GetAll)).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan", rt.Cache.Public(usecase.CacheGroupKegiatan, rt.Kegiatan.GetAll)).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan/upcoming", rt.Cache.Public(usecase.CacheGroupKegiatan, rt.Kegiatan.GetUpcoming)).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan.ics", rt.Calendar.Feed).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan/{id:[0-9]+}.ics", rt.Calendar.Event).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan/{id}", rt.Cache.Public(usecase.CacheGroupKegiatan, rt.Kegiatan.GetByID)).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan/slug/{slug}", rt.Cache.Public(usecase.CacheGroupKegiatan, rt.Kegiatan.GetBySlug)).Methods("GET")
+	publicAPI.HandleFunc("/kegiatan/{kegiatan_id}/photos", rt.Cache.Public(usecase.CacheGroupKegiatan, rt.KegiatanPhoto.GetByKegiatanID)).Methods("GET")
+	publicAPI.HandleFunc("/struktur", rt.Cache.Public(usecase.CacheGroupStruktur, rt.Struktur.GetAll)).Methods("GET")
+	publicAPI.HandleFunc("/pembina", rt.Cache.Public(usecase.CacheGroupPembina, rt.Pembina.GetAll)).Methods("GET")
+	publicAPI.HandleFunc("/qrcode/enabled", rt.Cache.Public(usecase.CacheGroupQRCode, rt.QRCode.GetEnabled)).Methods("GET")
+	publicAPI.HandleFunc("/categories", rt.Cache.Public(usecase.CacheGroupCategories, rt.Category.GetAll)).Methods("GET")
+	publicAPI.HandleFunc("/tags", rt.Cache.Public(usecase.CacheGroupTags, rt.Tag.GetAll)).Methods("GET")
+
+	// Protected admin routes
+	adminAPI := api.PathPrefix("/admin").Subrouter()
+	adminAPI.Use(rt.RateLimiter.Limit("admin", rt.AdminLimit))
+	adminAPI.Use(JWTMiddleware(rt.JWTSecret))
+
+	// Banner admin routes
+	adminAPI.HandleFunc("/banners", rt.Banner.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/banners/{id}", rt.Banner.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/banners", rt.Banner.Create).Methods("POST")
+	adminAPI.HandleFunc("/banners/{id}", rt.Banner.Update).Methods("PUT")
+	adminAPI.HandleFunc("/banners/{id}", rt.Banner.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/banners/{id}", rt.Banner.Delete).Methods("DELETE")
+
+	// Kegiatan admin routes
+	adminAPI.HandleFunc("/kegiatan", rt.Kegiatan.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/kegiatan/{id}", rt.Kegiatan.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/kegiatan", rt.Kegiatan.Create).Methods("POST")
+	adminAPI.HandleFunc("/kegiatan/{id}", rt.Kegiatan.Update).Methods("PUT")
+	adminAPI.HandleFunc("/kegiatan/{id}", rt.Kegiatan.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/kegiatan/{id}", rt.Kegiatan.Delete).Methods("DELETE")
+	adminAPI.HandleFunc("/kegiatan/{id}/revisions", rt.Kegiatan.GetRevisions).Methods("GET")
+	adminAPI.HandleFunc("/kegiatan/{id}/revisions/diff", rt.Kegiatan.DiffRevisions).Methods("GET")
+	adminAPI.HandleFunc("/kegiatan/{id}/revisions/{version}/restore", rt.Kegiatan.RestoreRevision).Methods("POST")
+
+	// Struktur admin routes
+	adminAPI.HandleFunc("/struktur", rt.Struktur.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/struktur/{id}", rt.Struktur.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/struktur", rt.Struktur.Create).Methods("POST")
+	adminAPI.HandleFunc("/struktur/{id}", rt.Struktur.Update).Methods("PUT")
+	adminAPI.HandleFunc("/struktur/{id}", rt.Struktur.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/struktur/{id}", rt.Struktur.Delete).Methods("DELETE")
+	adminAPI.HandleFunc("/struktur/{id}/revisions", rt.Struktur.GetRevisions).Methods("GET")
+	adminAPI.HandleFunc("/struktur/{id}/revisions/diff", rt.Struktur.DiffRevisions).Methods("GET")
+	adminAPI.HandleFunc("/struktur/{id}/revisions/{version}/restore", rt.Struktur.RestoreRevision).Methods("POST")
+
+	// Pembina admin routes
+	adminAPI.HandleFunc("/pembina", rt.Pembina.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/pembina/{id}", rt.Pembina.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/pembina", rt.Pembina.Create).Methods("POST")
+	adminAPI.HandleFunc("/pembina/{id}", rt.Pembina.Update).Methods("PUT")
+	adminAPI.HandleFunc("/pembina/{id}", rt.Pembina.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/pembina/{id}", rt.Pembina.Delete).Methods("DELETE")
+
+	// QR Code admin routes
+	adminAPI.HandleFunc("/qrcode", rt.QRCode.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/qrcode/{id}", rt.QRCode.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/qrcode", rt.QRCode.Create).Methods("POST")
+	adminAPI.HandleFunc("/qrcode/{id}", rt.QRCode.Update).Methods("PUT")
+	adminAPI.HandleFunc("/qrcode/{id}", rt.QRCode.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/qrcode/{id}", rt.QRCode.Delete).Methods("DELETE")
+	adminAPI.HandleFunc("/qrcode/{id}/toggle", rt.QRCode.ToggleEnable).Methods("PUT")
+
+	// Category admin routes
+	adminAPI.HandleFunc("/categories", rt.Category.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/categories/{id}", rt.Category.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/categories", rt.Category.Create).Methods("POST")
+	adminAPI.HandleFunc("/categories/{id}", rt.Category.Update).Methods("PUT")
+	adminAPI.HandleFunc("/categories/{id}", rt.Category.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/categories/{id}", rt.Category.Delete).Methods("DELETE")
+
+	// Tag admin routes
+	adminAPI.HandleFunc("/tags", rt.Tag.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/tags/{id}", rt.Tag.GetByID).Methods("GET")
+	adminAPI.HandleFunc("/tags", rt.Tag.Create).Methods("POST")
+	adminAPI.HandleFunc("/tags/{id}", rt.Tag.Update).Methods("PUT")
+	adminAPI.HandleFunc("/tags/{id}", rt.Tag.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/tags/{id}", rt.Tag.Delete).Methods("DELETE")
+
+	// Kegiatan Photos admin routes
+	adminAPI.HandleFunc("/kegiatan/{kegiatan_id}/photos", rt.KegiatanPhoto.Create).Methods("POST")
+	adminAPI.HandleFunc("/photos/{photo_id}", rt.KegiatanPhoto.Update).Methods("PUT")
+	adminAPI.HandleFunc("/photos/{photo_id}", rt.KegiatanPhoto.Patch).Methods("PATCH")
+	adminAPI.HandleFunc("/photos/{photo_id}", rt.KegiatanPhoto.Delete).Methods("DELETE")
+	adminAPI.HandleFunc("/photos/sort-order", rt.KegiatanPhoto.UpdateSortOrder).Methods("PUT")
+
+	// Response cache statistics
+	adminAPI.HandleFunc("/cache", rt.Cache.Stats).Methods("GET")
+
+	// Trash admin routes
+	adminAPI.HandleFunc("/trash", rt.Trash.GetAll).Methods("GET")
+	adminAPI.HandleFunc("/trash/{type}/{id}/restore", rt.Trash.Restore).Methods("POST")
+	adminAPI.HandleFunc("/trash/{type}/{id}", rt.Trash.Purge).Methods("DELETE")
+
+	// Upload routes (protected)
+	adminAPI.HandleFunc("/upload/image", rt.Upload.UploadImage).Methods("POST")
+	adminAPI.HandleFunc("/upload/images", rt.Upload.UploadMultipleImages).Methods("POST")
+
+	// Static file serving for uploads
+	router.PathPrefix("/uploads/").Handler(ServeUploads(rt.UploadsDir))
+
+	return router
+}
@@ -2,9 +2,10 @@ package http
 
 import (
 	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
 	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -13,18 +14,20 @@ import (
 
 type StrukturHandler struct {
 	strukturUsecase usecase.StrukturUsecase
+	validator       *validation.Validator
 }
 
-func NewStrukturHandler(strukturUsecase usecase.StrukturUsecase) *StrukturHandler {
+func NewStrukturHandler(strukturUsecase usecase.StrukturUsecase, validator *validation.Validator) *StrukturHandler {
 	return &StrukturHandler{
 		strukturUsecase: strukturUsecase,
+		validator:       validator,
 	}
 }
 
 func (h *StrukturHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	struktur, err := h.strukturUsecase.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -39,21 +42,22 @@ func (h *StrukturHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	struktur, err := h.strukturUsecase.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if struktur == nil {
-		http.Error(w, "Struktur not found", http.StatusNotFound)
+		writeError(w, r, errStrukturNotFound)
 		return
 	}
 
+	setETag(w, struktur.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -64,12 +68,15 @@ func (h *StrukturHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *StrukturHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var struktur entity.Struktur
 	if err := json.NewDecoder(r.Body).Decode(&struktur); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
+	if !validRequest(w, r, h.validator, &struktur) {
+		return
+	}
 	if err := h.strukturUsecase.Create(r.Context(), &struktur); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -86,25 +93,43 @@ func (h *StrukturHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var struktur entity.Struktur
 	if err := json.NewDecoder(r.Body).Decode(&struktur); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeBodyError(w, r, err, errInvalidJSON)
 		return
 	}
 
-	// Debug log
-	fmt.Printf("Update handler received data: ID=%d, Nama=%s, NRA=%s\n", id, struktur.Nama, struktur.NRA)
-
 	struktur.ID = id
+	if struktur.Version, err = ifMatchVersion(r, struktur.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	current, err := h.strukturUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current == nil {
+		writeError(w, r, errStrukturNotFound)
+		return
+	}
+	if !validUpdate(w, r, h.validator, &struktur, current) {
+		return
+	}
 	if err := h.strukturUsecase.Update(r.Context(), &struktur); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.strukturUsecase.GetByID, id, errStrukturNotFound)
+			return
+		}
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, struktur.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -117,12 +142,15 @@ func (h *StrukturHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	if err := h.strukturUsecase.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == repository.ErrNoRowsAffected {
+			err = errStrukturNotFound
+		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -132,3 +160,121 @@ func (h *StrukturHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Struktur deleted successfully",
 	})
 }
+
+func (h *StrukturHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, _, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	revisions, err := h.strukturUsecase.GetRevisions(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeRevisions(w, revisions)
+}
+
+func (h *StrukturHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, _, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	from, to, err := diffVersions(r)
+	if err != nil {
+		writeError(w, r, errInvalidRevisionRange)
+		return
+	}
+
+	diff, err := h.strukturUsecase.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+func (h *StrukturHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, version, err := revisionParams(r)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	existing, err := h.strukturUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if existing == nil {
+		writeError(w, r, errStrukturNotFound)
+		return
+	}
+
+	// The restore is an update: it needs the version the client last saw, or the one just read
+	currentVersion, err := ifMatchVersion(r, existing.Version)
+	if err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	if err := h.strukturUsecase.RestoreRevision(r.Context(), id, version, currentVersion); err != nil {
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.strukturUsecase.GetByID, id, errStrukturNotFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	struktur, err := h.strukturUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, struktur.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    struktur,
+		"message": "Struktur restored to revision " + strconv.Itoa(version),
+	})
+}
+
+// strukturPatchFields are the members a PATCH may change. "version" works like If-Match.
+var strukturPatchFields = patchFields{
+	"nama":     requiredString,
+	"jabatan":  requiredString,
+	"prodi":    nil,
+	"angkatan": nil,
+	"nra":      nil,
+	"foto_url": nil,
+	"version":  nil,
+}
+
+// Patch applies a JSON merge patch to a struktur member and returns it as stored afterwards.
+func (h *StrukturHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Struktur]{
+		fields:   strukturPatchFields,
+		load:     h.strukturUsecase.GetByID,
+		update:   h.strukturUsecase.Update,
+		notFound: errStrukturNotFound,
+		message:  "Struktur updated successfully",
+	})
+}
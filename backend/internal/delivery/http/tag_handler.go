@@ -0,0 +1,179 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/internal/usecase"
+	"arshaka-backend/internal/validation"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TagHandler struct {
+	tagUsecase usecase.TagUsecase
+	validator  *validation.Validator
+}
+
+func NewTagHandler(tagUsecase usecase.TagUsecase, validator *validation.Validator) *TagHandler {
+	return &TagHandler{
+		tagUsecase: tagUsecase,
+		validator:  validator,
+	}
+}
+
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.tagUsecase.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if tags == nil {
+		tags = []entity.Tag{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    tags,
+	})
+}
+
+func (h *TagHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	tag, err := h.tagUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if tag == nil {
+		writeError(w, r, errTagNotFound)
+		return
+	}
+
+	setETag(w, tag.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    tag,
+	})
+}
+
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var tag entity.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		writeBodyError(w, r, err, errInvalidJSON)
+		return
+	}
+
+	if !validRequest(w, r, h.validator, &tag) {
+		return
+	}
+	if err := h.tagUsecase.Create(r.Context(), &tag); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    tag,
+		"message": "Tag created successfully",
+	})
+}
+
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	var tag entity.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		writeBodyError(w, r, err, errInvalidJSON)
+		return
+	}
+
+	tag.ID = id
+	if tag.Version, err = ifMatchVersion(r, tag.Version); err != nil {
+		writeError(w, r, errInvalidIfMatch)
+		return
+	}
+	if !validRequest(w, r, h.validator, &tag) {
+		return
+	}
+	if err := h.tagUsecase.Update(r.Context(), &tag); err != nil {
+		if err == repository.ErrNoRowsAffected {
+			writeStale(w, r, h.tagUsecase.GetByID, id, errTagNotFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, tag.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    tag,
+		"message": "Tag updated successfully",
+	})
+}
+
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.tagUsecase.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Tag deleted successfully",
+	})
+}
+
+// tagPatchFields are the members a PATCH may change. "version" works like If-Match.
+var tagPatchFields = patchFields{
+	"name":    requiredString,
+	"slug":    nil,
+	"version": nil,
+}
+
+// Patch applies a JSON merge patch to a tag and returns it as stored afterwards.
+func (h *TagHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	patchEntity(w, r, id, h.validator, patchSpec[entity.Tag]{
+		fields:   tagPatchFields,
+		load:     h.tagUsecase.GetByID,
+		update:   h.tagUsecase.Update,
+		notFound: errTagNotFound,
+		message:  "Tag updated successfully",
+	})
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/usecase"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TrashHandler struct {
+	trashUsecase usecase.TrashUsecase
+}
+
+func NewTrashHandler(trashUsecase usecase.TrashUsecase) *TrashHandler {
+	return &TrashHandler{
+		trashUsecase: trashUsecase,
+	}
+}
+
+func (h *TrashHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	items, err := h.trashUsecase.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if items == nil {
+		items = []entity.TrashItem{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    items,
+	})
+}
+
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.trashUsecase.Restore(r.Context(), vars["type"], id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Item restored successfully",
+	})
+}
+
+func (h *TrashHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.trashUsecase.Purge(r.Context(), vars["type"], id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Item permanently deleted",
+	})
+}
@@ -1,32 +1,87 @@
 package http
 
 import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/metrics"
+	"arshaka-backend/internal/repository"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 )
 
-type UploadHandler struct{}
+type UploadHandler struct {
+	uploadedFileRepo repository.UploadedFileRepository
+	uploadsDir       string
+	maxSize          int64
+	maxBatchSize     int64
+	metrics          *metrics.Metrics
+}
+
+// NewUploadHandler stores images in uploadsDir. A single upload may be at most maxSize bytes,
+// a multi-image upload at most maxBatchSize.
+func NewUploadHandler(uploadedFileRepo repository.UploadedFileRepository, uploadsDir string, maxSize, maxBatchSize int64, m *metrics.Metrics) *UploadHandler {
+	return &UploadHandler{
+		uploadedFileRepo: uploadedFileRepo,
+		uploadsDir:       uploadsDir,
+		maxSize:          maxSize,
+		maxBatchSize:     maxBatchSize,
+		metrics:          m,
+	}
+}
+
+// recordUpload tracks a saved file in uploaded_files so it can later be referenced,
+// e.g. as an inline image in a kegiatan description. The file itself is already on
+// disk, so a tracking failure is logged rather than failing the upload.
+func (h *UploadHandler) recordUpload(r *http.Request, header *multipart.FileHeader, filename, filePath string, size int64) int {
+	h.metrics.ObserveUpload(size, nil)
+
+	uploadContext := r.FormValue("context")
+	if uploadContext == "" {
+		uploadContext = "general"
+	}
+
+	uploadedBy := "admin"
+	if user, ok := GetUserFromContext(r.Context()); ok {
+		uploadedBy = user.Username
+	}
+
+	file := &entity.UploadedFile{
+		Filename:         filename,
+		OriginalFilename: header.Filename,
+		FilePath:         filePath,
+		FileURL:          fmt.Sprintf("/uploads/%s", filename),
+		FileSize:         size,
+		MimeType:         header.Header.Get("Content-Type"),
+		UploadedBy:       uploadedBy,
+		UploadContext:    uploadContext,
+	}
+	if err := h.uploadedFileRepo.Create(r.Context(), file); err != nil {
+		slog.ErrorContext(r.Context(), "failed to record upload", "file", filename, "error", err)
+		return 0
+	}
 
-func NewUploadHandler() *UploadHandler {
-	return &UploadHandler{}
+	return file.ID
 }
 
 func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
-	err := r.ParseMultipartForm(10 << 20) // 10 MB max
+	limitBody(w, r, h.maxSize)
+	err := r.ParseMultipartForm(h.maxSize)
 	if err != nil {
-		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		writeFormError(w, r, err)
 		return
 	}
 
 	file, header, err := r.FormFile("image")
 	if err != nil {
-		http.Error(w, "Unable to get file from form", http.StatusBadRequest)
+		writeError(w, r, errFileRequired)
 		return
 	}
 	defer file.Close()
@@ -34,7 +89,8 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Validate file type
 	contentType := header.Header.Get("Content-Type")
 	if !isValidImageType(contentType) {
-		http.Error(w, "Invalid file type. Only JPEG, PNG, and GIF are allowed", http.StatusBadRequest)
+		h.metrics.ObserveUpload(0, errInvalidFileType)
+		writeError(w, r, errInvalidFileType)
 		return
 	}
 
@@ -43,7 +99,7 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), generateRandomString(8), ext)
 
 	// Create uploads directory if it doesn't exist
-	uploadsDir := "./uploads"
+	uploadsDir := h.uploadsDir
 	if _, err := os.Stat(uploadsDir); os.IsNotExist(err) {
 		os.MkdirAll(uploadsDir, 0755)
 	}
@@ -54,32 +110,47 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Create destination file
 	dst, err := os.Create(filePath)
 	if err != nil {
-		http.Error(w, "Unable to create file", http.StatusInternalServerError)
+		h.metrics.ObserveUpload(0, err)
+		writeError(w, r, errFileSaveFailed)
 		return
 	}
 	defer dst.Close()
 
 	// Copy uploaded file to destination
-	_, err = io.Copy(dst, file)
+	size, err := io.Copy(dst, file)
 	if err != nil {
-		http.Error(w, "Unable to save file", http.StatusInternalServerError)
+		h.metrics.ObserveUpload(0, err)
+		writeError(w, r, errFileSaveFailed)
 		return
 	}
 
 	// Return file URL
 	fileURL := fmt.Sprintf("/uploads/%s", filename)
+	fileID := h.recordUpload(r, header, filename, filePath, size)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"data": map[string]string{
+		"data": map[string]interface{}{
 			"url":      fileURL,
 			"filename": filename,
+			"file_id":  fileID,
 		},
 		"message": "File uploaded successfully",
 	})
 }
 
+// writeFormError answers a multipart form that could not be read, telling an oversized body
+// apart from a malformed one.
+func writeFormError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, errFileTooLarge)
+		return
+	}
+	writeError(w, r, errInvalidForm)
+}
+
 func isValidImageType(contentType string) bool {
 	validTypes := []string{
 		"image/jpeg",
@@ -107,20 +178,21 @@ func generateRandomString(length int) string {
 
 func (h *UploadHandler) UploadMultipleImages(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
-	err := r.ParseMultipartForm(50 << 20) // 50 MB max for multiple files
+	limitBody(w, r, h.maxBatchSize)
+	err := r.ParseMultipartForm(h.maxBatchSize)
 	if err != nil {
-		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		writeFormError(w, r, err)
 		return
 	}
 
 	files := r.MultipartForm.File["images"]
 	if len(files) == 0 {
-		http.Error(w, "No files provided", http.StatusBadRequest)
+		writeError(w, r, errFileRequired)
 		return
 	}
 
-	var uploadedFiles []map[string]string
-	uploadsDir := "./uploads"
+	var uploadedFiles []map[string]interface{}
+	uploadsDir := h.uploadsDir
 
 	// Create uploads directory if it doesn't exist
 	if _, err := os.Stat(uploadsDir); os.IsNotExist(err) {
@@ -130,6 +202,7 @@ func (h *UploadHandler) UploadMultipleImages(w http.ResponseWriter, r *http.Requ
 	for _, fileHeader := range files {
 		file, err := fileHeader.Open()
 		if err != nil {
+			h.metrics.ObserveUpload(0, err)
 			continue
 		}
 		defer file.Close()
@@ -137,6 +210,7 @@ func (h *UploadHandler) UploadMultipleImages(w http.ResponseWriter, r *http.Requ
 		// Validate file type
 		contentType := fileHeader.Header.Get("Content-Type")
 		if !isValidImageType(contentType) {
+			h.metrics.ObserveUpload(0, errInvalidFileType)
 			continue
 		}
 
@@ -148,19 +222,22 @@ func (h *UploadHandler) UploadMultipleImages(w http.ResponseWriter, r *http.Requ
 		// Create destination file
 		dst, err := os.Create(filePath)
 		if err != nil {
+			h.metrics.ObserveUpload(0, err)
 			continue
 		}
 		defer dst.Close()
 
 		// Copy uploaded file to destination
-		_, err = io.Copy(dst, file)
+		size, err := io.Copy(dst, file)
 		if err != nil {
+			h.metrics.ObserveUpload(0, err)
 			continue
 		}
 
-		uploadedFiles = append(uploadedFiles, map[string]string{
+		uploadedFiles = append(uploadedFiles, map[string]interface{}{
 			"url":      fmt.Sprintf("/uploads/%s", filename),
 			"filename": filename,
+			"file_id":  h.recordUpload(r, fileHeader, filename, filePath, size),
 		})
 	}
 
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// immutableCacheControl lets browsers and CDNs keep an upload for a year without revalidating.
+// Upload names carry a timestamp and random suffix, so a changed image always gets a new URL.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// uploadsCSP applies when an upload is opened directly: nothing in it may run scripts, load
+// resources or reach the site's cookies, which matters for SVG images.
+const uploadsCSP = "default-src 'none'; img-src 'self'; style-src 'unsafe-inline'; sandbox"
+
+// ServeUploads serves the uploaded files in dir under /uploads/. Directories are not listed, the
+// content type is never sniffed, and anything that is not an image is sent as a download.
+func ServeUploads(dir string) http.Handler {
+	files := http.StripPrefix("/uploads/", http.FileServer(filesOnly{http.Dir(dir)}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Content-Security-Policy", uploadsCSP)
+
+		name := path.Base(r.URL.Path)
+		if contentType := mime.TypeByExtension(path.Ext(name)); !strings.HasPrefix(contentType, "image/") {
+			header.Set("Content-Type", "application/octet-stream")
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		}
+		files.ServeHTTP(&uploadWriter{ResponseWriter: w}, r)
+	})
+}
+
+// filesOnly hides directories, so neither listings nor index.html pages are served.
+type filesOnly struct {
+	fs http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+// uploadWriter marks only files it actually serves as immutable, so a 404 for a file that is
+// uploaded a moment later is not cached.
+type uploadWriter struct {
+	http.ResponseWriter
+}
+
+func (w *uploadWriter) WriteHeader(status int) {
+	header := w.Header()
+	if status == http.StatusOK || status == http.StatusPartialContent || status == http.StatusNotModified {
+		header.Set("Cache-Control", immutableCacheControl)
+	} else {
+		// Errors are plain text from http.FileServer, not the file
+		header.Del("Content-Disposition")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *uploadWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
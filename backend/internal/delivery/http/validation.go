@@ -0,0 +1,26 @@
+package http
+
+import (
+	"arshaka-backend/internal/validation"
+	"net/http"
+)
+
+// validRequest checks v against its validate tags. When a rule is broken it answers with the
+// failing fields and returns false.
+func validRequest(w http.ResponseWriter, r *http.Request, validator *validation.Validator, v interface{}) bool {
+	if err := validator.Struct(r.Context(), v); err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// validUpdate is validRequest for the new state of stored, whose upload URLs are accepted as
+// they are.
+func validUpdate(w http.ResponseWriter, r *http.Request, validator *validation.Validator, v, stored interface{}) bool {
+	if err := validator.Update(r.Context(), v, stored); err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	return true
+}
@@ -4,7 +4,7 @@ import "time"
 
 type AdminUser struct {
 	ID           int       `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username"`
+	Username     string    `json:"username" db:"username" validate:"required,max=50"`
 	PasswordHash string    `json:"-" db:"password_hash"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
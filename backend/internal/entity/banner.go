@@ -3,8 +3,10 @@ package entity
 import "time"
 
 type Banner struct {
-	ID        int       `json:"id" db:"id"`
-	ImageURL  string    `json:"image_url" db:"image_url"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        int        `json:"id" db:"id"`
+	ImageURL  string     `json:"image_url" db:"image_url" validate:"required,max=255,upload"`
+	Version   int        `json:"version" db:"version"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
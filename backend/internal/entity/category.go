@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID            int       `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name" validate:"required,max=100"`
+	Slug          string    `json:"slug" db:"slug"`
+	Description   string    `json:"description" db:"description" validate:"maxbytes=65535"`
+	KegiatanCount int       `json:"kegiatan_count"`
+	Version       int       `json:"version" db:"version"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
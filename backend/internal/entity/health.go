@@ -0,0 +1,29 @@
+package entity
+
+// Readiness check results.
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheck is the outcome of one readiness check.
+type HealthCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Detail    string  `json:"detail,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Readiness is "ok" only when every check passed.
+type Readiness struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// BuildInfo identifies the running binary and the schema version it expects.
+type BuildInfo struct {
+	Commit        string `json:"commit"`
+	BuildTime     string `json:"build_time"`
+	SchemaVersion int    `json:"schema_version"`
+	GoVersion     string `json:"go_version"`
+}
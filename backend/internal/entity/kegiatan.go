@@ -2,23 +2,113 @@ package entity
 
 import "time"
 
+const (
+	KegiatanStatusDraft     = "draft"
+	KegiatanStatusPublished = "published"
+	KegiatanStatusArchived  = "archived"
+)
+
+const (
+	KegiatanStateUpcoming = "upcoming"
+	KegiatanStateOngoing  = "ongoing"
+	KegiatanStatePast     = "past"
+)
+
+// DefaultTimezone is used for kegiatan that do not specify their own timezone.
+const DefaultTimezone = "Asia/Jakarta"
+
 type Kegiatan struct {
-	ID        int            `json:"id" db:"id"`
-	Judul     string         `json:"judul" db:"judul"`
-	Deskripsi string         `json:"deskripsi" db:"deskripsi"`
-	Cover     string         `json:"cover" db:"cover"`
-	Tanggal   time.Time      `json:"tanggal" db:"tanggal"`
-	CreatedAt time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
-	Fotos     []KegiatanFoto `json:"fotos,omitempty"`
+	ID        int    `json:"id" db:"id"`
+	Judul     string `json:"judul" db:"judul" validate:"required,max=255"`
+	Slug      string `json:"slug" db:"slug"`
+	Deskripsi string `json:"deskripsi" db:"deskripsi" validate:"maxbytes=16777215"`
+	// DeskripsiHTML is Deskripsi rendered from Markdown and sanitized, safe to embed as-is
+	DeskripsiHTML string     `json:"deskripsi_html" db:"deskripsi_html"`
+	Cover         string     `json:"cover" db:"cover" validate:"max=255,upload"`
+	Tanggal       time.Time  `json:"tanggal" db:"tanggal" validate:"required_without=StartAt"`
+	StartAt       time.Time  `json:"start_at" db:"start_at"`
+	EndAt         time.Time  `json:"end_at" db:"end_at" validate:"gtefield=StartAt"`
+	Timezone      string     `json:"timezone" db:"timezone" validate:"max=64"`
+	State         string     `json:"state"`
+	Location      Location   `json:"location"`
+	Status        string     `json:"status" db:"status"`
+	PublishAt     *time.Time `json:"publish_at" db:"publish_at"`
+	Version       int        `json:"version" db:"version"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Photos are saved through their own endpoints and categories are referenced by ID, so
+	// neither is validated as part of a kegiatan
+	Fotos      []KegiatanFoto `json:"fotos,omitempty" validate:"-"`
+	Categories []Category     `json:"categories,omitempty" validate:"-"`
+	Tags       []Tag          `json:"tags,omitempty"`
+}
+
+// IsPublic reports whether the kegiatan may be shown to visitors at the given time.
+func (k *Kegiatan) IsPublic(now time.Time) bool {
+	if k.Status != KegiatanStatusPublished {
+		return false
+	}
+	return k.PublishAt == nil || !k.PublishAt.After(now)
+}
+
+// Location describes where a kegiatan takes place. Every field is optional.
+type Location struct {
+	Name      string   `json:"name" db:"location_name" validate:"max=255"`
+	Address   string   `json:"address" db:"location_address" validate:"max=500"`
+	Latitude  *float64 `json:"latitude" db:"latitude"`
+	Longitude *float64 `json:"longitude" db:"longitude"`
+}
+
+// EventState reports whether the kegiatan is upcoming, ongoing or past at the given time.
+func (k *Kegiatan) EventState(now time.Time) string {
+	switch {
+	case now.Before(k.StartAt):
+		return KegiatanStateUpcoming
+	case now.After(k.EndAt):
+		return KegiatanStatePast
+	default:
+		return KegiatanStateOngoing
+	}
+}
+
+// TimeLocation returns the kegiatan's timezone, falling back to DefaultTimezone when it is unknown.
+func (k *Kegiatan) TimeLocation() *time.Location {
+	if loc, err := time.LoadLocation(k.Timezone); err == nil && k.Timezone != "" {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// KegiatanFilter narrows down the kegiatan returned by GetAll.
+type KegiatanFilter struct {
+	PublishedOnly bool
+	CategorySlug  string
+	TagSlug       string
+	// EndsAfter, when set, skips kegiatan that finished before this time.
+	EndsAfter time.Time
+}
+
+// IsValidKegiatanStatus reports whether status is one of the known kegiatan statuses.
+func IsValidKegiatanStatus(status string) bool {
+	switch status {
+	case KegiatanStatusDraft, KegiatanStatusPublished, KegiatanStatusArchived:
+		return true
+	}
+	return false
 }
 
 type KegiatanFoto struct {
-	ID         int       `json:"id" db:"id"`
-	KegiatanID int       `json:"kegiatan_id" db:"kegiatan_id"`
-	ImageURL   string    `json:"image_url" db:"image_url"`
-	Caption    string    `json:"caption" db:"caption"`
-	SortOrder  int       `json:"sort_order" db:"sort_order"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID         int        `json:"id" db:"id"`
+	KegiatanID int        `json:"kegiatan_id" db:"kegiatan_id"`
+	ImageURL   string     `json:"image_url" db:"image_url" validate:"required,max=255,upload"`
+	Caption    string     `json:"caption" db:"caption" validate:"maxbytes=65535"`
+	SortOrder  int        `json:"sort_order" db:"sort_order"`
+	Version    int        `json:"version" db:"version"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
@@ -3,11 +3,13 @@ package entity
 import "time"
 
 type Pembina struct {
-	ID        int       `json:"id" db:"id"`
-	Nama      string    `json:"nama" db:"nama"`
-	Jabatan   string    `json:"jabatan" db:"jabatan"`
-	NIP       string    `json:"nip" db:"nip"`
-	FotoURL   string    `json:"foto_url" db:"foto_url"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        int        `json:"id" db:"id"`
+	Nama      string     `json:"nama" db:"nama" validate:"required,max=100"`
+	Jabatan   string     `json:"jabatan" db:"jabatan" validate:"required,max=100"`
+	NIP       string     `json:"nip" db:"nip" validate:"max=50"`
+	FotoURL   string     `json:"foto_url" db:"foto_url" validate:"max=255,upload"`
+	Version   int        `json:"version" db:"version"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
@@ -3,10 +3,12 @@ package entity
 import "time"
 
 type QRCode struct {
-	ID         int       `json:"id" db:"id"`
-	ImageURL   string    `json:"image_url" db:"image_url"`
-	Keterangan string    `json:"keterangan" db:"keterangan"`
-	Enable     bool      `json:"enable" db:"enable"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID         int        `json:"id" db:"id"`
+	ImageURL   string     `json:"image_url" db:"image_url" validate:"required,max=255,upload"`
+	Keterangan string     `json:"keterangan" db:"keterangan" validate:"maxbytes=65535"`
+	Enable     bool       `json:"enable" db:"enable"`
+	Version    int        `json:"version" db:"version"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
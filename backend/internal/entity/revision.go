@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is a versioned snapshot of an entity taken each time it is saved.
+type Revision struct {
+	ID         int             `json:"id" db:"id"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   int             `json:"entity_id" db:"entity_id"`
+	Version    int             `json:"version" db:"version"`
+	Snapshot   json.RawMessage `json:"snapshot" db:"snapshot"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// FieldChange is a single field that differs between two revisions.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RevisionDiff struct {
+	EntityType  string        `json:"entity_type"`
+	EntityID    int           `json:"entity_id"`
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Changes     []FieldChange `json:"changes"`
+}
@@ -3,13 +3,15 @@ package entity
 import "time"
 
 type Struktur struct {
-	ID        int       `json:"id" db:"id"`
-	Nama      string    `json:"nama" db:"nama"`
-	Jabatan   string    `json:"jabatan" db:"jabatan"`
-	Prodi     string    `json:"prodi" db:"prodi"`
-	Angkatan  string    `json:"angkatan" db:"angkatan"`
-	NRA       string    `json:"nra" db:"nra"`
-	FotoURL   string    `json:"foto_url" db:"foto_url"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        int        `json:"id" db:"id"`
+	Nama      string     `json:"nama" db:"nama" validate:"required,max=100"`
+	Jabatan   string     `json:"jabatan" db:"jabatan" validate:"required,max=100"`
+	Prodi     string     `json:"prodi" db:"prodi" validate:"max=100"`
+	Angkatan  string     `json:"angkatan" db:"angkatan" validate:"max=10"`
+	NRA       string     `json:"nra" db:"nra" validate:"max=20,nra"`
+	FotoURL   string     `json:"foto_url" db:"foto_url" validate:"max=255,upload"`
+	Version   int        `json:"version" db:"version"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
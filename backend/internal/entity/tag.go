@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Tag struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name" validate:"required,max=50"`
+	Slug      string    `json:"slug" db:"slug"`
+	Version   int       `json:"version" db:"version"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Content types that can be moved to the trash.
+const (
+	TrashTypeKegiatan      = "kegiatan"
+	TrashTypeKegiatanPhoto = "kegiatan_photo"
+	TrashTypeBanner        = "banner"
+	TrashTypeStruktur      = "struktur"
+	TrashTypePembina       = "pembina"
+	TrashTypeQRCode        = "qrcode"
+)
+
+// TrashItem is a deleted row of any content type as shown in the admin trash.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	ImageURL  string    `json:"image_url,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// IsValidTrashType reports whether itemType is one of the trash content types.
+func IsValidTrashType(itemType string) bool {
+	switch itemType {
+	case TrashTypeKegiatan, TrashTypeKegiatanPhoto, TrashTypeBanner, TrashTypeStruktur, TrashTypePembina, TrashTypeQRCode:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// Package metrics holds the Prometheus collectors of the backend. All methods are safe to call on
+// a nil *Metrics, which records nothing.
+package metrics
+
+import (
+	"arshaka-backend/pkg/cache"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "arshaka"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	uploads      *prometheus.CounterVec
+	uploadBytes  prometheus.Counter
+	logins       *prometheus.CounterVec
+	jobRuns      *prometheus.CounterVec
+	jobDuration  *prometheus.HistogramVec
+}
+
+// New registers the backend metrics, the connection pool stats of db and the Go runtime and
+// process metrics on a registry of its own.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to answer HTTP requests by method and route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		uploads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uploads_total",
+			Help:      "Uploaded images by result (success or failure).",
+		}, []string{"result"}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes of successfully stored uploads.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Admin login attempts by result (success or failure).",
+		}, []string{"result"}),
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "job_runs_total",
+			Help:      "Background job runs by job and result (success or failure).",
+		}, []string{"job", "result"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "job_duration_seconds",
+			Help:      "Time background job runs took.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"job"}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.uploads, m.uploadBytes,
+		m.logins,
+		m.jobRuns, m.jobDuration,
+		collectors.NewDBStatsCollector(db, "arshaka"),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a finished HTTP request. route is the template, never the raw path, so
+// IDs in URLs do not create a series each.
+func (m *Metrics) ObserveRequest(method, route string, status int, took time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(took.Seconds())
+}
+
+// ObserveUpload records one uploaded file; size is only counted when it was stored.
+func (m *Metrics) ObserveUpload(size int64, err error) {
+	if m == nil {
+		return
+	}
+	m.uploads.WithLabelValues(result(err)).Inc()
+	if err == nil {
+		m.uploadBytes.Add(float64(size))
+	}
+}
+
+func (m *Metrics) ObserveLogin(err error) {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(result(err)).Inc()
+}
+
+func (m *Metrics) ObserveJob(job string, took time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.jobRuns.WithLabelValues(job, result(err)).Inc()
+	m.jobDuration.WithLabelValues(job).Observe(took.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// WatchCache exports the hit, miss and invalidation counters and the entry count of c per group.
+func (m *Metrics) WatchCache(c *cache.Cache) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(cacheCollector{cache: c})
+}
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(namespace+"_cache_hits_total",
+		"Public API responses served from the cache by group.", []string{"group"}, nil)
+	cacheMissesDesc = prometheus.NewDesc(namespace+"_cache_misses_total",
+		"Public API requests the cache could not answer by group.", []string{"group"}, nil)
+	cacheInvalidationsDesc = prometheus.NewDesc(namespace+"_cache_invalidations_total",
+		"Times a group was dropped from the cache after its data changed.", []string{"group"}, nil)
+	cacheEntriesDesc = prometheus.NewDesc(namespace+"_cache_entries",
+		"Responses currently cached by group.", []string{"group"}, nil)
+)
+
+// cacheCollector reads the counters at scrape time, so the cache needs no Prometheus types.
+type cacheCollector struct {
+	cache *cache.Cache
+}
+
+func (c cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheInvalidationsDesc
+	ch <- cacheEntriesDesc
+}
+
+func (c cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for group, s := range c.cache.Stats() {
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(s.Hits), group)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(s.Misses), group)
+		ch <- prometheus.MustNewConstMetric(cacheInvalidationsDesc, prometheus.CounterValue, float64(s.Invalidations), group)
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(s.Entries), group)
+	}
+}
@@ -1,15 +1,29 @@
 package repository
 
 import (
+	"arshaka-backend/internal/apperror"
 	"arshaka-backend/internal/entity"
 	"context"
-	"errors"
+	"time"
 )
 
 var (
-	ErrMaxPembinaReached = errors.New("maksimal 2 pembina sudah tercapai")
+	ErrMaxPembinaReached = apperror.New(apperror.KindConflict, "pembina_limit_reached")
+	ErrCategoryExists    = apperror.New(apperror.KindConflict, "category_exists")
+	ErrTagExists         = apperror.New(apperror.KindConflict, "tag_exists")
+	ErrNotInTrash        = apperror.New(apperror.KindNotFound, "not_in_trash")
+	// ErrNoRowsAffected is returned by Update when no row matched: it is missing, or its version
+	// no longer matches the one the caller read. Delete returns it when the row is missing or
+	// already in the trash.
+	ErrNoRowsAffected = apperror.New(apperror.KindPreconditionFailed, "not_updated")
 )
 
+// Transactor runs fn in one database transaction, committed when fn returns nil. Repository
+// calls made with the context passed to fn take part in it.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type AdminRepository interface {
 	GetByUsername(ctx context.Context, username string) (*entity.AdminUser, error)
 }
@@ -20,17 +34,51 @@ type BannerRepository interface {
 	Create(ctx context.Context, banner *entity.Banner) error
 	Update(ctx context.Context, banner *entity.Banner) error
 	Delete(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context) ([]entity.Banner, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 type KegiatanRepository interface {
-	GetAll(ctx context.Context) ([]entity.Kegiatan, error)
+	GetAll(ctx context.Context, filter entity.KegiatanFilter) ([]entity.Kegiatan, error)
 	GetByID(ctx context.Context, id int) (*entity.Kegiatan, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Kegiatan, error)
 	Create(ctx context.Context, kegiatan *entity.Kegiatan) error
 	Update(ctx context.Context, kegiatan *entity.Kegiatan) error
 	Delete(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context) ([]entity.Kegiatan, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	GetWithoutSlug(ctx context.Context) ([]entity.Kegiatan, error)
+	UpdateSlug(ctx context.Context, id int, slug string) error
+	SlugTaken(ctx context.Context, slug string, excludeID int) (bool, error)
+	GetSlugRedirect(ctx context.Context, oldSlug string) (string, error)
+	AddSlugHistory(ctx context.Context, kegiatanID int, slug string) error
+	DeleteSlugHistory(ctx context.Context, kegiatanID int, slug string) error
 	GetFotosByKegiatanID(ctx context.Context, kegiatanID int) ([]entity.KegiatanFoto, error)
 	CreateFoto(ctx context.Context, foto *entity.KegiatanFoto) error
 	DeleteFotosByKegiatanID(ctx context.Context, kegiatanID int) error
+	PublishDue(ctx context.Context, now time.Time) (int, error)
+	CountStateChanges(ctx context.Context, from, to time.Time) (int, error)
+	SetCategories(ctx context.Context, kegiatanID int, categoryIDs []int) error
+	SetTags(ctx context.Context, kegiatanID int, tagIDs []int) error
+}
+
+type CategoryRepository interface {
+	GetAll(ctx context.Context, publishedOnly bool) ([]entity.Category, error)
+	GetByID(ctx context.Context, id int) (*entity.Category, error)
+	Create(ctx context.Context, category *entity.Category) error
+	Update(ctx context.Context, category *entity.Category) error
+	Delete(ctx context.Context, id int) error
+}
+
+type TagRepository interface {
+	GetAll(ctx context.Context) ([]entity.Tag, error)
+	GetByID(ctx context.Context, id int) (*entity.Tag, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Tag, error)
+	Create(ctx context.Context, tag *entity.Tag) error
+	Update(ctx context.Context, tag *entity.Tag) error
+	Delete(ctx context.Context, id int) error
 }
 
 type StrukturRepository interface {
@@ -39,6 +87,9 @@ type StrukturRepository interface {
 	Create(ctx context.Context, struktur *entity.Struktur) error
 	Update(ctx context.Context, struktur *entity.Struktur) error
 	Delete(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context) ([]entity.Struktur, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 type QRCodeRepository interface {
@@ -49,6 +100,9 @@ type QRCodeRepository interface {
 	Update(ctx context.Context, qrcode *entity.QRCode) error
 	Delete(ctx context.Context, id int) error
 	ToggleEnable(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context) ([]entity.QRCode, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 type PembinaRepository interface {
@@ -58,4 +112,21 @@ type PembinaRepository interface {
 	Update(ctx context.Context, pembina *entity.Pembina) error
 	Delete(ctx context.Context, id int) error
 	Count(ctx context.Context) (int, error)
+	GetDeleted(ctx context.Context) ([]entity.Pembina, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+}
+
+type RevisionRepository interface {
+	// Create stores the snapshot as the next version of the entity
+	Create(ctx context.Context, revision *entity.Revision) error
+	GetByEntity(ctx context.Context, entityType string, entityID int) ([]entity.Revision, error)
+	GetByVersion(ctx context.Context, entityType string, entityID, version int) (*entity.Revision, error)
+	DeleteByEntity(ctx context.Context, entityType string, entityID int) error
+}
+
+type SchemaRepository interface {
+	Ping(ctx context.Context) error
+	// AppliedMigrations lists the migration files recorded in schema_migrations
+	AppliedMigrations(ctx context.Context) ([]string, error)
 }
@@ -16,7 +16,7 @@ func NewBannerRepository(db *sql.DB) repository.BannerRepository {
 }
 
 func (r *bannerRepository) GetAll(ctx context.Context) ([]entity.Banner, error) {
-	query := "SELECT id, image_url, created_at, updated_at FROM banners ORDER BY created_at DESC"
+	query := "SELECT id, image_url, version, created_at, updated_at FROM banners WHERE deleted_at IS NULL ORDER BY created_at DESC"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -26,7 +26,7 @@ func (r *bannerRepository) GetAll(ctx context.Context) ([]entity.Banner, error)
 	var banners []entity.Banner
 	for rows.Next() {
 		var banner entity.Banner
-		err := rows.Scan(&banner.ID, &banner.ImageURL, &banner.CreatedAt, &banner.UpdatedAt)
+		err := rows.Scan(&banner.ID, &banner.ImageURL, &banner.Version, &banner.CreatedAt, &banner.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -37,11 +37,11 @@ func (r *bannerRepository) GetAll(ctx context.Context) ([]entity.Banner, error)
 }
 
 func (r *bannerRepository) GetByID(ctx context.Context, id int) (*entity.Banner, error) {
-	query := "SELECT id, image_url, created_at, updated_at FROM banners WHERE id = ?"
+	query := "SELECT id, image_url, version, created_at, updated_at FROM banners WHERE id = ? AND deleted_at IS NULL"
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var banner entity.Banner
-	err := row.Scan(&banner.ID, &banner.ImageURL, &banner.CreatedAt, &banner.UpdatedAt)
+	err := row.Scan(&banner.ID, &banner.ImageURL, &banner.Version, &banner.CreatedAt, &banner.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -69,13 +69,45 @@ func (r *bannerRepository) Create(ctx context.Context, banner *entity.Banner) er
 }
 
 func (r *bannerRepository) Update(ctx context.Context, banner *entity.Banner) error {
-	query := "UPDATE banners SET image_url = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, banner.ImageURL, banner.ID)
-	return err
+	query := "UPDATE banners SET image_url = ?, " + bumpVersion + " WHERE id = ? AND deleted_at IS NULL AND " + versionMatch
+	version, err := execVersioned(ctx, r.db, "banners", banner.ID, query, banner.ImageURL, banner.ID, banner.Version, banner.Version)
+	if err != nil {
+		return err
+	}
+
+	banner.Version = version
+	return nil
 }
 
 func (r *bannerRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM banners WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "banners", id)
+}
+
+func (r *bannerRepository) GetDeleted(ctx context.Context) ([]entity.Banner, error) {
+	query := "SELECT id, image_url, version, created_at, updated_at, deleted_at FROM banners WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var banners []entity.Banner
+	for rows.Next() {
+		var banner entity.Banner
+		err := rows.Scan(&banner.ID, &banner.ImageURL, &banner.Version, &banner.CreatedAt, &banner.UpdatedAt, &banner.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		banners = append(banners, banner)
+	}
+
+	return banners, nil
+}
+
+func (r *bannerRepository) Restore(ctx context.Context, id int) error {
+	return restoreDeleted(ctx, r.db, "banners", id)
+}
+
+func (r *bannerRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "banners", id)
 }
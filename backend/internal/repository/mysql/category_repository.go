@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) repository.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+// GetAll returns every category with the number of kegiatan in it. When publishedOnly is set
+// only kegiatan visible to the public are counted.
+func (r *categoryRepository) GetAll(ctx context.Context, publishedOnly bool) ([]entity.Category, error) {
+	joinCondition := "k.id = kc.kegiatan_id AND k.deleted_at IS NULL"
+	var args []interface{}
+	if publishedOnly {
+		joinCondition += " AND k.status = ? AND (k.publish_at IS NULL OR k.publish_at <= ?)"
+		args = append(args, entity.KegiatanStatusPublished, time.Now())
+	}
+
+	query := `
+		SELECT c.id, c.name, c.slug, COALESCE(c.description, ''), c.version, c.created_at, c.updated_at, COUNT(k.id)
+		FROM categories c
+		LEFT JOIN kegiatan_categories kc ON kc.category_id = c.id
+		LEFT JOIN kegiatan k ON ` + joinCondition + `
+		GROUP BY c.id, c.name, c.slug, c.description, c.version, c.created_at, c.updated_at
+		ORDER BY c.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entity.Category
+	for rows.Next() {
+		var c entity.Category
+		err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.Version, &c.CreatedAt, &c.UpdatedAt, &c.KegiatanCount)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id int) (*entity.Category, error) {
+	query := `
+		SELECT c.id, c.name, c.slug, COALESCE(c.description, ''), c.version, c.created_at, c.updated_at,
+		       (SELECT COUNT(*) FROM kegiatan_categories kc JOIN kegiatan k ON k.id = kc.kegiatan_id
+		        WHERE kc.category_id = c.id AND k.deleted_at IS NULL)
+		FROM categories c
+		WHERE c.id = ?
+	`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	var c entity.Category
+	err := row.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.Version, &c.CreatedAt, &c.UpdatedAt, &c.KegiatanCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *entity.Category) error {
+	query := "INSERT INTO categories (name, slug, description) VALUES (?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, category.Name, category.Slug, category.Description)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return repository.ErrCategoryExists
+		}
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	category.ID = int(id)
+	return nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *entity.Category) error {
+	query := "UPDATE categories SET name = ?, slug = ?, description = ?, " + bumpVersion + " WHERE id = ? AND " + versionMatch
+	version, err := execVersioned(ctx, r.db, "categories", category.ID, query,
+		category.Name, category.Slug, category.Description, category.ID, category.Version, category.Version)
+	if isDuplicateEntry(err) {
+		return repository.ErrCategoryExists
+	}
+	if err != nil {
+		return err
+	}
+
+	category.Version = version
+	return nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM categories WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
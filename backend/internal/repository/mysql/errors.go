@@ -0,0 +1,23 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+// isDuplicateEntry reports whether err is a MySQL unique key violation.
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// isDeadlock reports whether err is a MySQL deadlock, after which the statement may be retried.
+func isDeadlock(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1213
+}
+
+// likeEscaper escapes the LIKE wildcards so a value can be matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
@@ -17,9 +17,9 @@ func NewKegiatanPhotoRepository(db *sql.DB) *KegiatanPhotoRepository {
 
 func (r *KegiatanPhotoRepository) GetByKegiatanID(ctx context.Context, kegiatanID int) ([]entity.KegiatanFoto, error) {
 	query := `
-		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, created_at, updated_at
+		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, version, created_at, updated_at
 		FROM kegiatan_photos
-		WHERE kegiatan_id = ?
+		WHERE kegiatan_id = ? AND deleted_at IS NULL
 		ORDER BY sort_order ASC, created_at ASC
 	`
 
@@ -38,6 +38,7 @@ func (r *KegiatanPhotoRepository) GetByKegiatanID(ctx context.Context, kegiatanI
 			&photo.ImageURL,
 			&photo.Caption,
 			&photo.SortOrder,
+			&photo.Version,
 			&photo.CreatedAt,
 			&photo.UpdatedAt,
 		)
@@ -73,25 +74,28 @@ func (r *KegiatanPhotoRepository) Create(ctx context.Context, photo *entity.Kegi
 func (r *KegiatanPhotoRepository) Update(ctx context.Context, photo *entity.KegiatanFoto) error {
 	query := `
 		UPDATE kegiatan_photos
-		SET photo_url = ?, caption = ?, sort_order = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
+		SET photo_url = ?, caption = ?, sort_order = ?, updated_at = CURRENT_TIMESTAMP, ` + bumpVersion + `
+		WHERE id = ? AND deleted_at IS NULL AND ` + versionMatch
 
-	_, err := r.db.ExecContext(ctx, query, photo.ImageURL, photo.Caption, photo.SortOrder, photo.ID)
-	return err
+	version, err := execVersioned(ctx, r.db, "kegiatan_photos", photo.ID, query,
+		photo.ImageURL, photo.Caption, photo.SortOrder, photo.ID, photo.Version, photo.Version)
+	if err != nil {
+		return err
+	}
+
+	photo.Version = version
+	return nil
 }
 
 func (r *KegiatanPhotoRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM kegiatan_photos WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "kegiatan_photos", id)
 }
 
 func (r *KegiatanPhotoRepository) UpdateSortOrder(ctx context.Context, photoID int, sortOrder int) error {
 	query := `
 		UPDATE kegiatan_photos 
-		SET sort_order = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
+		SET sort_order = ?, updated_at = CURRENT_TIMESTAMP, ` + bumpVersion + `
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query, sortOrder, photoID)
@@ -100,9 +104,9 @@ func (r *KegiatanPhotoRepository) UpdateSortOrder(ctx context.Context, photoID i
 
 func (r *KegiatanPhotoRepository) GetByID(ctx context.Context, id int) (*entity.KegiatanFoto, error) {
 	query := `
-		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, created_at, updated_at
+		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, version, created_at, updated_at
 		FROM kegiatan_photos
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var photo entity.KegiatanFoto
@@ -112,13 +116,64 @@ func (r *KegiatanPhotoRepository) GetByID(ctx context.Context, id int) (*entity.
 		&photo.ImageURL,
 		&photo.Caption,
 		&photo.SortOrder,
+		&photo.Version,
 		&photo.CreatedAt,
 		&photo.UpdatedAt,
 	)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	return &photo, nil
 }
+
+// GetDeleted returns the photos that were deleted one by one. Photos of a trashed kegiatan
+// are not listed here since they come back with the kegiatan.
+func (r *KegiatanPhotoRepository) GetDeleted(ctx context.Context) ([]entity.KegiatanFoto, error) {
+	query := `
+		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, version, created_at, updated_at, deleted_at
+		FROM kegiatan_photos
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var photos []entity.KegiatanFoto
+	for rows.Next() {
+		var photo entity.KegiatanFoto
+		err := rows.Scan(
+			&photo.ID,
+			&photo.KegiatanID,
+			&photo.ImageURL,
+			&photo.Caption,
+			&photo.SortOrder,
+			&photo.Version,
+			&photo.CreatedAt,
+			&photo.UpdatedAt,
+			&photo.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, photo)
+	}
+
+	return photos, nil
+}
+
+func (r *KegiatanPhotoRepository) Restore(ctx context.Context, id int) error {
+	return restoreDeleted(ctx, r.db, "kegiatan_photos", id)
+}
+
+func (r *KegiatanPhotoRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "kegiatan_photos", id)
+}
@@ -5,6 +5,8 @@ import (
 	"arshaka-backend/internal/repository"
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 )
 
 type kegiatanRepository struct {
@@ -15,9 +17,92 @@ func NewKegiatanRepository(db *sql.DB) repository.KegiatanRepository {
 	return &kegiatanRepository{db: db}
 }
 
-func (r *kegiatanRepository) GetAll(ctx context.Context) ([]entity.Kegiatan, error) {
-	query := "SELECT id, judul, deskripsi, cover, tanggal, created_at, updated_at FROM kegiatan ORDER BY tanggal DESC"
-	rows, err := r.db.QueryContext(ctx, query)
+const kegiatanColumns = "id, judul, slug, deskripsi, deskripsi_html, cover, tanggal, start_at, end_at, timezone, " +
+	"location_name, location_address, latitude, longitude, status, publish_at, version, created_at, updated_at, deleted_at"
+
+// start_at and end_at are stored as wall-clock time in the kegiatan's own timezone.
+const wallClockLayout = "2006-01-02 15:04:05"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKegiatan(row rowScanner, k *entity.Kegiatan) error {
+	var slug, deskripsiHTML, locationName, locationAddress sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var publishAt, deletedAt sql.NullTime
+	err := row.Scan(&k.ID, &k.Judul, &slug, &k.Deskripsi, &deskripsiHTML, &k.Cover, &k.Tanggal, &k.StartAt, &k.EndAt, &k.Timezone,
+		&locationName, &locationAddress, &latitude, &longitude, &k.Status, &publishAt, &k.Version, &k.CreatedAt, &k.UpdatedAt, &deletedAt)
+	if err != nil {
+		return err
+	}
+	k.Slug = slug.String
+	k.DeskripsiHTML = deskripsiHTML.String
+
+	loc := k.TimeLocation()
+	k.StartAt = fromWallClock(k.StartAt, loc)
+	k.EndAt = fromWallClock(k.EndAt, loc)
+
+	k.Location.Name = locationName.String
+	k.Location.Address = locationAddress.String
+	if latitude.Valid && longitude.Valid {
+		k.Location.Latitude = &latitude.Float64
+		k.Location.Longitude = &longitude.Float64
+	}
+	if publishAt.Valid {
+		k.PublishAt = &publishAt.Time
+	}
+	if deletedAt.Valid {
+		k.DeletedAt = &deletedAt.Time
+	}
+	return nil
+}
+
+func toWallClock(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(wallClockLayout)
+}
+
+func fromWallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullableSlug stores an empty slug as NULL so the unique index ignores it.
+func nullableSlug(slug string) sql.NullString {
+	return sql.NullString{String: slug, Valid: slug != ""}
+}
+
+func (r *kegiatanRepository) GetAll(ctx context.Context, filter entity.KegiatanFilter) ([]entity.Kegiatan, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if filter.PublishedOnly {
+		conditions = append(conditions, "status = ? AND (publish_at IS NULL OR publish_at <= ?)")
+		args = append(args, entity.KegiatanStatusPublished, time.Now())
+	}
+	if !filter.EndsAfter.IsZero() {
+		// end_at is local wall-clock time, so compare with a margin wide enough for any UTC offset
+		conditions = append(conditions, "end_at >= ?")
+		args = append(args, filter.EndsAfter.UTC().Add(-14*time.Hour).Format(wallClockLayout))
+	}
+	if filter.CategorySlug != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM kegiatan_categories kc JOIN categories c ON c.id = kc.category_id
+			WHERE kc.kegiatan_id = kegiatan.id AND c.slug = ?)`)
+		args = append(args, filter.CategorySlug)
+	}
+	if filter.TagSlug != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM kegiatan_tags kt JOIN tags t ON t.id = kt.tag_id
+			WHERE kt.kegiatan_id = kegiatan.id AND t.slug = ?)`)
+		args = append(args, filter.TagSlug)
+	}
+
+	query := "SELECT " + kegiatanColumns + " FROM kegiatan WHERE " + strings.Join(conditions, " AND ") + " ORDER BY tanggal DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,30 +111,29 @@ func (r *kegiatanRepository) GetAll(ctx context.Context) ([]entity.Kegiatan, err
 	var kegiatan []entity.Kegiatan
 	for rows.Next() {
 		var k entity.Kegiatan
-		err := rows.Scan(&k.ID, &k.Judul, &k.Deskripsi, &k.Cover, &k.Tanggal, &k.CreatedAt, &k.UpdatedAt)
-		if err != nil {
+		if err := scanKegiatan(rows, &k); err != nil {
 			return nil, err
 		}
-
-		// Get photos for each kegiatan
-		fotos, err := r.GetFotosByKegiatanID(ctx, k.ID)
-		if err != nil {
-			return nil, err
-		}
-		k.Fotos = fotos
-
 		kegiatan = append(kegiatan, k)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get photos, categories and tags for all kegiatan at once
+	if err := r.loadRelations(ctx, kegiatan); err != nil {
+		return nil, err
+	}
 
 	return kegiatan, nil
 }
 
 func (r *kegiatanRepository) GetByID(ctx context.Context, id int) (*entity.Kegiatan, error) {
-	query := "SELECT id, judul, deskripsi, cover, tanggal, created_at, updated_at FROM kegiatan WHERE id = ?"
+	query := "SELECT " + kegiatanColumns + " FROM kegiatan WHERE id = ? AND deleted_at IS NULL"
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var k entity.Kegiatan
-	err := row.Scan(&k.ID, &k.Judul, &k.Deskripsi, &k.Cover, &k.Tanggal, &k.CreatedAt, &k.UpdatedAt)
+	err := scanKegiatan(row, &k)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -57,19 +141,55 @@ func (r *kegiatanRepository) GetByID(ctx context.Context, id int) (*entity.Kegia
 		return nil, err
 	}
 
-	// Get photos
-	fotos, err := r.GetFotosByKegiatanID(ctx, id)
+	// Get photos, categories and tags
+	loaded := []entity.Kegiatan{k}
+	if err := r.loadRelations(ctx, loaded); err != nil {
+		return nil, err
+	}
+
+	return &loaded[0], nil
+}
+
+func (r *kegiatanRepository) GetBySlug(ctx context.Context, slug string) (*entity.Kegiatan, error) {
+	query := "SELECT " + kegiatanColumns + " FROM kegiatan WHERE slug = ? AND deleted_at IS NULL"
+	row := r.db.QueryRowContext(ctx, query, slug)
+
+	var k entity.Kegiatan
+	err := scanKegiatan(row, &k)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
-	k.Fotos = fotos
 
-	return &k, nil
+	loaded := []entity.Kegiatan{k}
+	if err := r.loadRelations(ctx, loaded); err != nil {
+		return nil, err
+	}
+
+	return &loaded[0], nil
 }
 
+// Create inserts the kegiatan together with the categories and tags it lists, all or nothing.
+// Tags must exist already; nil lists link nothing.
 func (r *kegiatanRepository) Create(ctx context.Context, kegiatan *entity.Kegiatan) error {
-	query := "INSERT INTO kegiatan (judul, deskripsi, cover, tanggal) VALUES (?, ?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, kegiatan.Judul, kegiatan.Deskripsi, kegiatan.Cover, kegiatan.Tanggal)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	loc := kegiatan.TimeLocation()
+	query := `
+		INSERT INTO kegiatan (judul, slug, deskripsi, deskripsi_html, cover, tanggal, start_at, end_at, timezone,
+			location_name, location_address, latitude, longitude, status, publish_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.ExecContext(ctx, query, kegiatan.Judul, nullableSlug(kegiatan.Slug), kegiatan.Deskripsi, kegiatan.DeskripsiHTML, kegiatan.Cover, kegiatan.Tanggal,
+		toWallClock(kegiatan.StartAt, loc), toWallClock(kegiatan.EndAt, loc), kegiatan.Timezone,
+		nullableString(kegiatan.Location.Name), nullableString(kegiatan.Location.Address), kegiatan.Location.Latitude, kegiatan.Location.Longitude,
+		kegiatan.Status, kegiatan.PublishAt)
 	if err != nil {
 		return err
 	}
@@ -79,33 +199,327 @@ func (r *kegiatanRepository) Create(ctx context.Context, kegiatan *entity.Kegiat
 		return err
 	}
 
+	categoryIDs := make([]int, 0, len(kegiatan.Categories))
+	for _, c := range kegiatan.Categories {
+		categoryIDs = append(categoryIDs, c.ID)
+	}
+	if err := replaceLinks(ctx, tx, "kegiatan_categories", "category_id", int(id), categoryIDs); err != nil {
+		return err
+	}
+	tagIDs := make([]int, 0, len(kegiatan.Tags))
+	for _, t := range kegiatan.Tags {
+		tagIDs = append(tagIDs, t.ID)
+	}
+	if err := replaceLinks(ctx, tx, "kegiatan_tags", "tag_id", int(id), tagIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	kegiatan.ID = int(id)
 	return nil
 }
 
 func (r *kegiatanRepository) Update(ctx context.Context, kegiatan *entity.Kegiatan) error {
-	query := "UPDATE kegiatan SET judul = ?, deskripsi = ?, cover = ?, tanggal = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, kegiatan.Judul, kegiatan.Deskripsi, kegiatan.Cover, kegiatan.Tanggal, kegiatan.ID)
-	return err
+	loc := kegiatan.TimeLocation()
+	query := `
+		UPDATE kegiatan SET judul = ?, slug = ?, deskripsi = ?, deskripsi_html = ?, cover = ?, tanggal = ?, start_at = ?, end_at = ?, timezone = ?,
+			location_name = ?, location_address = ?, latitude = ?, longitude = ?, status = ?, publish_at = ?, ` + bumpVersion + `
+		WHERE id = ? AND deleted_at IS NULL AND ` + versionMatch
+	version, err := execVersioned(ctx, r.db, "kegiatan", kegiatan.ID, query,
+		kegiatan.Judul, nullableSlug(kegiatan.Slug), kegiatan.Deskripsi, kegiatan.DeskripsiHTML, kegiatan.Cover, kegiatan.Tanggal,
+		toWallClock(kegiatan.StartAt, loc), toWallClock(kegiatan.EndAt, loc), kegiatan.Timezone,
+		nullableString(kegiatan.Location.Name), nullableString(kegiatan.Location.Address), kegiatan.Location.Latitude, kegiatan.Location.Longitude,
+		kegiatan.Status, kegiatan.PublishAt, kegiatan.ID, kegiatan.Version, kegiatan.Version)
+	if err != nil {
+		return err
+	}
+
+	kegiatan.Version = version
+	return nil
 }
 
+// Delete moves the kegiatan to the trash. Its photos stay untouched so a restore brings them back.
 func (r *kegiatanRepository) Delete(ctx context.Context, id int) error {
-	// Delete photos first (cascade should handle this, but let's be explicit)
-	err := r.DeleteFotosByKegiatanID(ctx, id)
+	return softDelete(ctx, r.db, "kegiatan", id)
+}
+
+func (r *kegiatanRepository) GetDeleted(ctx context.Context) ([]entity.Kegiatan, error) {
+	query := "SELECT " + kegiatanColumns + " FROM kegiatan WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var kegiatan []entity.Kegiatan
+	for rows.Next() {
+		var k entity.Kegiatan
+		if err := scanKegiatan(rows, &k); err != nil {
+			return nil, err
+		}
+		kegiatan = append(kegiatan, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRelations(ctx, kegiatan); err != nil {
+		return nil, err
+	}
+
+	return kegiatan, nil
+}
+
+func (r *kegiatanRepository) Restore(ctx context.Context, id int) error {
+	return restoreDeleted(ctx, r.db, "kegiatan", id)
+}
+
+// Purge permanently removes a trashed kegiatan. Photos, taxonomy links and slug history
+// go with it through their foreign keys.
+func (r *kegiatanRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "kegiatan", id)
+}
+
+func (r *kegiatanRepository) GetWithoutSlug(ctx context.Context) ([]entity.Kegiatan, error) {
+	query := "SELECT " + kegiatanColumns + " FROM kegiatan WHERE slug IS NULL OR slug = '' ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	query := "DELETE FROM kegiatan WHERE id = ?"
-	_, err = r.db.ExecContext(ctx, query, id)
+	var kegiatan []entity.Kegiatan
+	for rows.Next() {
+		var k entity.Kegiatan
+		if err := scanKegiatan(rows, &k); err != nil {
+			return nil, err
+		}
+		kegiatan = append(kegiatan, k)
+	}
+
+	return kegiatan, rows.Err()
+}
+
+func (r *kegiatanRepository) UpdateSlug(ctx context.Context, id int, slug string) error {
+	query := "UPDATE kegiatan SET slug = ?, " + bumpVersion + " WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, nullableSlug(slug), id)
 	return err
 }
 
+// SlugTaken reports whether slug is used by another kegiatan, either currently or in its history.
+func (r *kegiatanRepository) SlugTaken(ctx context.Context, slug string, excludeID int) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM kegiatan WHERE slug = ? AND id <> ?)
+		    OR EXISTS (SELECT 1 FROM kegiatan_slug_history WHERE slug = ? AND kegiatan_id <> ?)
+	`
+	var taken bool
+	err := r.db.QueryRowContext(ctx, query, slug, excludeID, slug, excludeID).Scan(&taken)
+	return taken, err
+}
+
+// GetSlugRedirect returns the current slug of the kegiatan that used to be reachable under oldSlug.
+func (r *kegiatanRepository) GetSlugRedirect(ctx context.Context, oldSlug string) (string, error) {
+	query := `
+		SELECT k.slug
+		FROM kegiatan_slug_history h
+		JOIN kegiatan k ON k.id = h.kegiatan_id
+		WHERE h.slug = ? AND k.slug IS NOT NULL AND k.deleted_at IS NULL
+	`
+	var slug string
+	err := r.db.QueryRowContext(ctx, query, oldSlug).Scan(&slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return slug, nil
+}
+
+func (r *kegiatanRepository) AddSlugHistory(ctx context.Context, kegiatanID int, slug string) error {
+	query := "INSERT IGNORE INTO kegiatan_slug_history (kegiatan_id, slug) VALUES (?, ?)"
+	_, err := r.db.ExecContext(ctx, query, kegiatanID, slug)
+	return err
+}
+
+func (r *kegiatanRepository) DeleteSlugHistory(ctx context.Context, kegiatanID int, slug string) error {
+	query := "DELETE FROM kegiatan_slug_history WHERE kegiatan_id = ? AND slug = ?"
+	_, err := r.db.ExecContext(ctx, query, kegiatanID, slug)
+	return err
+}
+
+// loadRelations fills in the photos, categories and tags of every kegiatan, with one query each.
+func (r *kegiatanRepository) loadRelations(ctx context.Context, kegiatan []entity.Kegiatan) error {
+	if len(kegiatan) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(kegiatan))
+	for i, k := range kegiatan {
+		ids[i] = k.ID
+	}
+
+	fotos, err := r.getFotosByKegiatanIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	categories, err := r.getCategoriesByKegiatanIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	tags, err := r.getTagsByKegiatanIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range kegiatan {
+		kegiatan[i].Fotos = fotos[kegiatan[i].ID]
+		kegiatan[i].Categories = categories[kegiatan[i].ID]
+		kegiatan[i].Tags = tags[kegiatan[i].ID]
+	}
+	return nil
+}
+
+// inList returns the placeholders and arguments for an IN (...) condition over ids.
+func inList(ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+func (r *kegiatanRepository) getFotosByKegiatanIDs(ctx context.Context, kegiatanIDs []int) (map[int][]entity.KegiatanFoto, error) {
+	placeholders, args := inList(kegiatanIDs)
+	query := `
+		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, version, created_at, updated_at
+		FROM kegiatan_photos
+		WHERE kegiatan_id IN (` + placeholders + `) AND deleted_at IS NULL
+		ORDER BY sort_order ASC, created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fotos := make(map[int][]entity.KegiatanFoto)
+	for rows.Next() {
+		var foto entity.KegiatanFoto
+		err := rows.Scan(&foto.ID, &foto.KegiatanID, &foto.ImageURL, &foto.Caption, &foto.SortOrder, &foto.Version, &foto.CreatedAt, &foto.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		fotos[foto.KegiatanID] = append(fotos[foto.KegiatanID], foto)
+	}
+
+	return fotos, rows.Err()
+}
+
+func (r *kegiatanRepository) getCategoriesByKegiatanIDs(ctx context.Context, kegiatanIDs []int) (map[int][]entity.Category, error) {
+	placeholders, args := inList(kegiatanIDs)
+	query := `
+		SELECT kc.kegiatan_id, c.id, c.name, c.slug, COALESCE(c.description, ''), c.created_at, c.updated_at
+		FROM categories c
+		JOIN kegiatan_categories kc ON kc.category_id = c.id
+		WHERE kc.kegiatan_id IN (` + placeholders + `)
+		ORDER BY c.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[int][]entity.Category)
+	for rows.Next() {
+		var kegiatanID int
+		var c entity.Category
+		err := rows.Scan(&kegiatanID, &c.ID, &c.Name, &c.Slug, &c.Description, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		categories[kegiatanID] = append(categories[kegiatanID], c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *kegiatanRepository) getTagsByKegiatanIDs(ctx context.Context, kegiatanIDs []int) (map[int][]entity.Tag, error) {
+	placeholders, args := inList(kegiatanIDs)
+	query := `
+		SELECT kt.kegiatan_id, t.id, t.name, t.slug, t.created_at
+		FROM tags t
+		JOIN kegiatan_tags kt ON kt.tag_id = t.id
+		WHERE kt.kegiatan_id IN (` + placeholders + `)
+		ORDER BY t.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]entity.Tag)
+	for rows.Next() {
+		var kegiatanID int
+		var t entity.Tag
+		err := rows.Scan(&kegiatanID, &t.ID, &t.Name, &t.Slug, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tags[kegiatanID] = append(tags[kegiatanID], t)
+	}
+
+	return tags, rows.Err()
+}
+
+// SetCategories replaces the categories linked to a kegiatan.
+func (r *kegiatanRepository) SetCategories(ctx context.Context, kegiatanID int, categoryIDs []int) error {
+	return r.setLinks(ctx, "kegiatan_categories", "category_id", kegiatanID, categoryIDs)
+}
+
+// SetTags replaces the tags linked to a kegiatan.
+func (r *kegiatanRepository) SetTags(ctx context.Context, kegiatanID int, tagIDs []int) error {
+	return r.setLinks(ctx, "kegiatan_tags", "tag_id", kegiatanID, tagIDs)
+}
+
+func (r *kegiatanRepository) setLinks(ctx context.Context, table, column string, kegiatanID int, ids []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceLinks(ctx, tx, table, column, kegiatanID, ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceLinks swaps the rows of a kegiatan in a link table for ids within tx.
+func replaceLinks(ctx context.Context, tx *sql.Tx, table, column string, kegiatanID int, ids []int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE kegiatan_id = ?", kegiatanID); err != nil {
+		return err
+	}
+
+	query := "INSERT IGNORE INTO " + table + " (kegiatan_id, " + column + ") VALUES (?, ?)"
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, query, kegiatanID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *kegiatanRepository) GetFotosByKegiatanID(ctx context.Context, kegiatanID int) ([]entity.KegiatanFoto, error) {
 	query := `
-		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, created_at, updated_at
+		SELECT id, kegiatan_id, photo_url as image_url, caption, sort_order, version, created_at, updated_at
 		FROM kegiatan_photos
-		WHERE kegiatan_id = ?
+		WHERE kegiatan_id = ? AND deleted_at IS NULL
 		ORDER BY sort_order ASC, created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query, kegiatanID)
@@ -117,7 +531,7 @@ func (r *kegiatanRepository) GetFotosByKegiatanID(ctx context.Context, kegiatanI
 	var fotos []entity.KegiatanFoto
 	for rows.Next() {
 		var foto entity.KegiatanFoto
-		err := rows.Scan(&foto.ID, &foto.KegiatanID, &foto.ImageURL, &foto.Caption, &foto.SortOrder, &foto.CreatedAt, &foto.UpdatedAt)
+		err := rows.Scan(&foto.ID, &foto.KegiatanID, &foto.ImageURL, &foto.Caption, &foto.SortOrder, &foto.Version, &foto.CreatedAt, &foto.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -148,3 +562,52 @@ func (r *kegiatanRepository) DeleteFotosByKegiatanID(ctx context.Context, kegiat
 	_, err := r.db.ExecContext(ctx, query, kegiatanID)
 	return err
 }
+
+// CountStateChanges counts the published kegiatan that became public, started or ended after
+// from and up to to.
+func (r *kegiatanRepository) CountStateChanges(ctx context.Context, from, to time.Time) (int, error) {
+	// start_at and end_at are local wall-clock time, so select with a margin wide enough for any
+	// UTC offset and compare exactly once they are converted
+	low := from.UTC().Add(-14 * time.Hour).Format(wallClockLayout)
+	high := to.UTC().Add(14 * time.Hour).Format(wallClockLayout)
+	query := "SELECT " + kegiatanColumns + ` FROM kegiatan
+		WHERE status = ? AND deleted_at IS NULL
+		AND ((publish_at > ? AND publish_at <= ?) OR start_at BETWEEN ? AND ? OR end_at BETWEEN ? AND ?)`
+	rows, err := r.db.QueryContext(ctx, query, entity.KegiatanStatusPublished, from, to, low, high, low, high)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	within := func(t time.Time) bool {
+		return t.After(from) && !t.After(to)
+	}
+	count := 0
+	for rows.Next() {
+		var k entity.Kegiatan
+		if err := scanKegiatan(rows, &k); err != nil {
+			return 0, err
+		}
+		if (k.PublishAt != nil && within(*k.PublishAt)) || within(k.StartAt) || within(k.EndAt) {
+			count++
+		}
+	}
+
+	return count, rows.Err()
+}
+
+// PublishDue publishes every draft whose publish_at has been reached and returns how many were flipped.
+func (r *kegiatanRepository) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	query := "UPDATE kegiatan SET status = ?, " + bumpVersion + " WHERE status = ? AND publish_at IS NOT NULL AND publish_at <= ? AND deleted_at IS NULL"
+	result, err := r.db.ExecContext(ctx, query, entity.KegiatanStatusPublished, entity.KegiatanStatusDraft, now)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
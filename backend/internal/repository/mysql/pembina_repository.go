@@ -16,7 +16,7 @@ func NewPembinaRepository(db *sql.DB) repository.PembinaRepository {
 }
 
 func (r *pembinaRepository) GetAll(ctx context.Context) ([]entity.Pembina, error) {
-	query := "SELECT id, nama, jabatan, nip, foto_url, created_at, updated_at FROM pembina ORDER BY created_at"
+	query := "SELECT id, nama, jabatan, nip, foto_url, version, created_at, updated_at FROM pembina WHERE deleted_at IS NULL ORDER BY created_at"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -26,7 +26,7 @@ func (r *pembinaRepository) GetAll(ctx context.Context) ([]entity.Pembina, error
 	var pembina []entity.Pembina
 	for rows.Next() {
 		var p entity.Pembina
-		err := rows.Scan(&p.ID, &p.Nama, &p.Jabatan, &p.NIP, &p.FotoURL, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Nama, &p.Jabatan, &p.NIP, &p.FotoURL, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -37,11 +37,11 @@ func (r *pembinaRepository) GetAll(ctx context.Context) ([]entity.Pembina, error
 }
 
 func (r *pembinaRepository) GetByID(ctx context.Context, id int) (*entity.Pembina, error) {
-	query := "SELECT id, nama, jabatan, nip, foto_url, created_at, updated_at FROM pembina WHERE id = ?"
+	query := "SELECT id, nama, jabatan, nip, foto_url, version, created_at, updated_at FROM pembina WHERE id = ? AND deleted_at IS NULL"
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var p entity.Pembina
-	err := row.Scan(&p.ID, &p.Nama, &p.Jabatan, &p.NIP, &p.FotoURL, &p.CreatedAt, &p.UpdatedAt)
+	err := row.Scan(&p.ID, &p.Nama, &p.Jabatan, &p.NIP, &p.FotoURL, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -53,7 +53,7 @@ func (r *pembinaRepository) GetByID(ctx context.Context, id int) (*entity.Pembin
 }
 
 func (r *pembinaRepository) Count(ctx context.Context) (int, error) {
-	query := "SELECT COUNT(*) FROM pembina"
+	query := "SELECT COUNT(*) FROM pembina WHERE deleted_at IS NULL"
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
 	return count, err
@@ -85,13 +85,55 @@ func (r *pembinaRepository) Create(ctx context.Context, pembina *entity.Pembina)
 }
 
 func (r *pembinaRepository) Update(ctx context.Context, pembina *entity.Pembina) error {
-	query := "UPDATE pembina SET nama = ?, jabatan = ?, nip = ?, foto_url = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, pembina.Nama, pembina.Jabatan, pembina.NIP, pembina.FotoURL, pembina.ID)
-	return err
+	query := "UPDATE pembina SET nama = ?, jabatan = ?, nip = ?, foto_url = ?, " + bumpVersion + " WHERE id = ? AND deleted_at IS NULL AND " + versionMatch
+	version, err := execVersioned(ctx, r.db, "pembina", pembina.ID, query,
+		pembina.Nama, pembina.Jabatan, pembina.NIP, pembina.FotoURL, pembina.ID, pembina.Version, pembina.Version)
+	if err != nil {
+		return err
+	}
+
+	pembina.Version = version
+	return nil
 }
 
 func (r *pembinaRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM pembina WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "pembina", id)
+}
+
+func (r *pembinaRepository) GetDeleted(ctx context.Context) ([]entity.Pembina, error) {
+	query := "SELECT id, nama, jabatan, nip, foto_url, version, created_at, updated_at, deleted_at FROM pembina WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pembina []entity.Pembina
+	for rows.Next() {
+		var p entity.Pembina
+		err := rows.Scan(&p.ID, &p.Nama, &p.Jabatan, &p.NIP, &p.FotoURL, &p.Version, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		pembina = append(pembina, p)
+	}
+
+	return pembina, nil
+}
+
+func (r *pembinaRepository) Restore(ctx context.Context, id int) error {
+	// A restored pembina counts towards the limit like a new one
+	count, err := r.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count >= 2 {
+		return repository.ErrMaxPembinaReached
+	}
+
+	return restoreDeleted(ctx, r.db, "pembina", id)
+}
+
+func (r *pembinaRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "pembina", id)
 }
@@ -16,7 +16,7 @@ func NewQRCodeRepository(db *sql.DB) repository.QRCodeRepository {
 }
 
 func (r *qrcodeRepository) GetAll(ctx context.Context) ([]entity.QRCode, error) {
-	query := "SELECT id, image_url, keterangan, enable, created_at, updated_at FROM qr_code ORDER BY created_at DESC"
+	query := "SELECT id, image_url, keterangan, enable, version, created_at, updated_at FROM qr_code WHERE deleted_at IS NULL ORDER BY created_at DESC"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -26,7 +26,7 @@ func (r *qrcodeRepository) GetAll(ctx context.Context) ([]entity.QRCode, error)
 	var qrcodes []entity.QRCode
 	for rows.Next() {
 		var qr entity.QRCode
-		err := rows.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.CreatedAt, &qr.UpdatedAt)
+		err := rows.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.Version, &qr.CreatedAt, &qr.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -37,7 +37,7 @@ func (r *qrcodeRepository) GetAll(ctx context.Context) ([]entity.QRCode, error)
 }
 
 func (r *qrcodeRepository) GetEnabled(ctx context.Context) ([]entity.QRCode, error) {
-	query := "SELECT id, image_url, keterangan, enable, created_at, updated_at FROM qr_code WHERE enable = true ORDER BY created_at DESC"
+	query := "SELECT id, image_url, keterangan, enable, version, created_at, updated_at FROM qr_code WHERE enable = true AND deleted_at IS NULL ORDER BY created_at DESC"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -47,7 +47,7 @@ func (r *qrcodeRepository) GetEnabled(ctx context.Context) ([]entity.QRCode, err
 	var qrcodes []entity.QRCode
 	for rows.Next() {
 		var qr entity.QRCode
-		err := rows.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.CreatedAt, &qr.UpdatedAt)
+		err := rows.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.Version, &qr.CreatedAt, &qr.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -58,11 +58,11 @@ func (r *qrcodeRepository) GetEnabled(ctx context.Context) ([]entity.QRCode, err
 }
 
 func (r *qrcodeRepository) GetByID(ctx context.Context, id int) (*entity.QRCode, error) {
-	query := "SELECT id, image_url, keterangan, enable, created_at, updated_at FROM qr_code WHERE id = ?"
+	query := "SELECT id, image_url, keterangan, enable, version, created_at, updated_at FROM qr_code WHERE id = ? AND deleted_at IS NULL"
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var qr entity.QRCode
-	err := row.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.CreatedAt, &qr.UpdatedAt)
+	err := row.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.Version, &qr.CreatedAt, &qr.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -90,19 +90,52 @@ func (r *qrcodeRepository) Create(ctx context.Context, qrcode *entity.QRCode) er
 }
 
 func (r *qrcodeRepository) Update(ctx context.Context, qrcode *entity.QRCode) error {
-	query := "UPDATE qr_code SET image_url = ?, keterangan = ?, enable = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, qrcode.ImageURL, qrcode.Keterangan, qrcode.Enable, qrcode.ID)
-	return err
+	query := "UPDATE qr_code SET image_url = ?, keterangan = ?, enable = ?, " + bumpVersion + " WHERE id = ? AND deleted_at IS NULL AND " + versionMatch
+	version, err := execVersioned(ctx, r.db, "qr_code", qrcode.ID, query,
+		qrcode.ImageURL, qrcode.Keterangan, qrcode.Enable, qrcode.ID, qrcode.Version, qrcode.Version)
+	if err != nil {
+		return err
+	}
+
+	qrcode.Version = version
+	return nil
 }
 
 func (r *qrcodeRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM qr_code WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "qr_code", id)
 }
 
 func (r *qrcodeRepository) ToggleEnable(ctx context.Context, id int) error {
-	query := "UPDATE qr_code SET enable = NOT enable WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := "UPDATE qr_code SET enable = NOT enable, " + bumpVersion + " WHERE id = ? AND deleted_at IS NULL"
+	_, err := execVersioned(ctx, r.db, "qr_code", id, query, id)
 	return err
 }
+
+func (r *qrcodeRepository) GetDeleted(ctx context.Context) ([]entity.QRCode, error) {
+	query := "SELECT id, image_url, keterangan, enable, version, created_at, updated_at, deleted_at FROM qr_code WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var qrcodes []entity.QRCode
+	for rows.Next() {
+		var qr entity.QRCode
+		err := rows.Scan(&qr.ID, &qr.ImageURL, &qr.Keterangan, &qr.Enable, &qr.Version, &qr.CreatedAt, &qr.UpdatedAt, &qr.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		qrcodes = append(qrcodes, qr)
+	}
+
+	return qrcodes, nil
+}
+
+func (r *qrcodeRepository) Restore(ctx context.Context, id int) error {
+	return restoreDeleted(ctx, r.db, "qr_code", id)
+}
+
+func (r *qrcodeRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "qr_code", id)
+}
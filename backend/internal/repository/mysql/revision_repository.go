@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type revisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) repository.RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+// revisionAttempts bounds how often Create retries when concurrent saves of the same entity pick
+// the same next version.
+const revisionAttempts = 5
+
+// Create stores revision as the next version of its entity. Two saves racing for that version hit
+// the unique key (or deadlock on the range lock of MAX) and the loser tries again with the version
+// after. Inside a transaction it tries once: the update of the entity row already orders saves of
+// the same entity, and a deadlock has rolled back everything the transaction did.
+func (r *revisionRepository) Create(ctx context.Context, revision *entity.Revision) error {
+	query := `
+		INSERT INTO revisions (entity_type, entity_id, version, snapshot)
+		SELECT ?, ?, COALESCE(MAX(version), 0) + 1, ?
+		FROM revisions
+		WHERE entity_type = ? AND entity_id = ?
+	`
+	db := conn(ctx, r.db)
+	attempts := revisionAttempts
+	if _, inTx := db.(*sql.Tx); inTx {
+		attempts = 1
+	}
+
+	var result sql.Result
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, err = db.ExecContext(ctx, query, revision.EntityType, revision.EntityID, []byte(revision.Snapshot),
+			revision.EntityType, revision.EntityID)
+		if !isDuplicateEntry(err) && !isDeadlock(err) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	revision.ID = int(id)
+	return db.QueryRowContext(ctx, "SELECT version, created_at FROM revisions WHERE id = ?", id).
+		Scan(&revision.Version, &revision.CreatedAt)
+}
+
+func (r *revisionRepository) GetByEntity(ctx context.Context, entityType string, entityID int) ([]entity.Revision, error) {
+	query := `
+		SELECT id, entity_type, entity_id, version, snapshot, created_at
+		FROM revisions
+		WHERE entity_type = ? AND entity_id = ?
+		ORDER BY version DESC
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []entity.Revision
+	for rows.Next() {
+		var rev entity.Revision
+		err := rows.Scan(&rev.ID, &rev.EntityType, &rev.EntityID, &rev.Version, &rev.Snapshot, &rev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *revisionRepository) GetByVersion(ctx context.Context, entityType string, entityID, version int) (*entity.Revision, error) {
+	query := `
+		SELECT id, entity_type, entity_id, version, snapshot, created_at
+		FROM revisions
+		WHERE entity_type = ? AND entity_id = ? AND version = ?
+	`
+	row := r.db.QueryRowContext(ctx, query, entityType, entityID, version)
+
+	var rev entity.Revision
+	err := row.Scan(&rev.ID, &rev.EntityType, &rev.EntityID, &rev.Version, &rev.Snapshot, &rev.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+func (r *revisionRepository) DeleteByEntity(ctx context.Context, entityType string, entityID int) error {
+	query := "DELETE FROM revisions WHERE entity_type = ? AND entity_id = ?"
+	_, err := r.db.ExecContext(ctx, query, entityType, entityID)
+	return err
+}
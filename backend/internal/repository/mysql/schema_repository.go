@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type schemaRepository struct {
+	db *sql.DB
+}
+
+func NewSchemaRepository(db *sql.DB) repository.SchemaRepository {
+	return &schemaRepository{db: db}
+}
+
+func (r *schemaRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *schemaRepository) AppliedMigrations(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT filename FROM schema_migrations ORDER BY filename")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
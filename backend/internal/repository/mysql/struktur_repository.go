@@ -16,7 +16,7 @@ func NewStrukturRepository(db *sql.DB) repository.StrukturRepository {
 }
 
 func (r *strukturRepository) GetAll(ctx context.Context) ([]entity.Struktur, error) {
-	query := "SELECT id, nama, jabatan, prodi, angkatan, nra, foto_url, created_at, updated_at FROM struktur ORDER BY created_at"
+	query := "SELECT id, nama, jabatan, prodi, angkatan, nra, foto_url, version, created_at, updated_at FROM struktur WHERE deleted_at IS NULL ORDER BY created_at"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -26,7 +26,7 @@ func (r *strukturRepository) GetAll(ctx context.Context) ([]entity.Struktur, err
 	var struktur []entity.Struktur
 	for rows.Next() {
 		var s entity.Struktur
-		err := rows.Scan(&s.ID, &s.Nama, &s.Jabatan, &s.Prodi, &s.Angkatan, &s.NRA, &s.FotoURL, &s.CreatedAt, &s.UpdatedAt)
+		err := rows.Scan(&s.ID, &s.Nama, &s.Jabatan, &s.Prodi, &s.Angkatan, &s.NRA, &s.FotoURL, &s.Version, &s.CreatedAt, &s.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -37,11 +37,11 @@ func (r *strukturRepository) GetAll(ctx context.Context) ([]entity.Struktur, err
 }
 
 func (r *strukturRepository) GetByID(ctx context.Context, id int) (*entity.Struktur, error) {
-	query := "SELECT id, nama, jabatan, prodi, angkatan, nra, foto_url, created_at, updated_at FROM struktur WHERE id = ?"
-	row := r.db.QueryRowContext(ctx, query, id)
+	query := "SELECT id, nama, jabatan, prodi, angkatan, nra, foto_url, version, created_at, updated_at FROM struktur WHERE id = ? AND deleted_at IS NULL"
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
 
 	var s entity.Struktur
-	err := row.Scan(&s.ID, &s.Nama, &s.Jabatan, &s.Prodi, &s.Angkatan, &s.NRA, &s.FotoURL, &s.CreatedAt, &s.UpdatedAt)
+	err := row.Scan(&s.ID, &s.Nama, &s.Jabatan, &s.Prodi, &s.Angkatan, &s.NRA, &s.FotoURL, &s.Version, &s.CreatedAt, &s.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -54,7 +54,7 @@ func (r *strukturRepository) GetByID(ctx context.Context, id int) (*entity.Struk
 
 func (r *strukturRepository) Create(ctx context.Context, struktur *entity.Struktur) error {
 	query := "INSERT INTO struktur (nama, jabatan, prodi, angkatan, nra, foto_url) VALUES (?, ?, ?, ?, ?, ?)"
-	result, err := r.db.ExecContext(ctx, query, struktur.Nama, struktur.Jabatan, struktur.Prodi, struktur.Angkatan, struktur.NRA, struktur.FotoURL)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, struktur.Nama, struktur.Jabatan, struktur.Prodi, struktur.Angkatan, struktur.NRA, struktur.FotoURL)
 	if err != nil {
 		return err
 	}
@@ -69,13 +69,46 @@ func (r *strukturRepository) Create(ctx context.Context, struktur *entity.Strukt
 }
 
 func (r *strukturRepository) Update(ctx context.Context, struktur *entity.Struktur) error {
-	query := "UPDATE struktur SET nama = ?, jabatan = ?, prodi = ?, angkatan = ?, nra = ?, foto_url = ? WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, struktur.Nama, struktur.Jabatan, struktur.Prodi, struktur.Angkatan, struktur.NRA, struktur.FotoURL, struktur.ID)
-	return err
+	query := "UPDATE struktur SET nama = ?, jabatan = ?, prodi = ?, angkatan = ?, nra = ?, foto_url = ?, " + bumpVersion + " WHERE id = ? AND deleted_at IS NULL AND " + versionMatch
+	version, err := execVersioned(ctx, conn(ctx, r.db), "struktur", struktur.ID, query,
+		struktur.Nama, struktur.Jabatan, struktur.Prodi, struktur.Angkatan, struktur.NRA, struktur.FotoURL, struktur.ID, struktur.Version, struktur.Version)
+	if err != nil {
+		return err
+	}
+
+	struktur.Version = version
+	return nil
 }
 
 func (r *strukturRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM struktur WHERE id = ?"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return softDelete(ctx, r.db, "struktur", id)
+}
+
+func (r *strukturRepository) GetDeleted(ctx context.Context) ([]entity.Struktur, error) {
+	query := "SELECT id, nama, jabatan, prodi, angkatan, nra, foto_url, version, created_at, updated_at, deleted_at FROM struktur WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var struktur []entity.Struktur
+	for rows.Next() {
+		var s entity.Struktur
+		err := rows.Scan(&s.ID, &s.Nama, &s.Jabatan, &s.Prodi, &s.Angkatan, &s.NRA, &s.FotoURL, &s.Version, &s.CreatedAt, &s.UpdatedAt, &s.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		struktur = append(struktur, s)
+	}
+
+	return struktur, nil
+}
+
+func (r *strukturRepository) Restore(ctx context.Context, id int) error {
+	return restoreDeleted(ctx, r.db, "struktur", id)
+}
+
+func (r *strukturRepository) Purge(ctx context.Context, id int) error {
+	return purgeDeleted(ctx, r.db, "struktur", id)
 }
@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) repository.TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) GetAll(ctx context.Context) ([]entity.Tag, error) {
+	query := "SELECT id, name, slug, version, created_at FROM tags ORDER BY name"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []entity.Tag
+	for rows.Next() {
+		var t entity.Tag
+		err := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.Version, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int) (*entity.Tag, error) {
+	query := "SELECT id, name, slug, version, created_at FROM tags WHERE id = ?"
+	return r.getOne(ctx, query, id)
+}
+
+func (r *tagRepository) GetBySlug(ctx context.Context, slug string) (*entity.Tag, error) {
+	query := "SELECT id, name, slug, version, created_at FROM tags WHERE slug = ?"
+	return r.getOne(ctx, query, slug)
+}
+
+func (r *tagRepository) getOne(ctx context.Context, query string, arg interface{}) (*entity.Tag, error) {
+	row := r.db.QueryRowContext(ctx, query, arg)
+
+	var t entity.Tag
+	err := row.Scan(&t.ID, &t.Name, &t.Slug, &t.Version, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *entity.Tag) error {
+	query := "INSERT INTO tags (name, slug) VALUES (?, ?)"
+	result, err := r.db.ExecContext(ctx, query, tag.Name, tag.Slug)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return repository.ErrTagExists
+		}
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	tag.ID = int(id)
+	return nil
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *entity.Tag) error {
+	query := "UPDATE tags SET name = ?, slug = ?, " + bumpVersion + " WHERE id = ? AND " + versionMatch
+	version, err := execVersioned(ctx, r.db, "tags", tag.ID, query, tag.Name, tag.Slug, tag.ID, tag.Version, tag.Version)
+	if isDuplicateEntry(err) {
+		return repository.ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+
+	tag.Version = version
+	return nil
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM tags WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
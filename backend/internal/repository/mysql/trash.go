@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+)
+
+// Rows are never removed by Delete. They get a deleted_at timestamp instead, stay out of every
+// regular query and can be restored until the retention job purges them. softDelete returns
+// repository.ErrNoRowsAffected when the row is missing or already in the trash.
+func softDelete(ctx context.Context, db *sql.DB, table string, id int) error {
+	query := "UPDATE " + table + " SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	result, err := db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNoRowsAffected
+	}
+	return nil
+}
+
+func restoreDeleted(ctx context.Context, db *sql.DB, table string, id int) error {
+	query := "UPDATE " + table + " SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+	return execInTrash(ctx, db, query, id)
+}
+
+func purgeDeleted(ctx context.Context, db *sql.DB, table string, id int) error {
+	query := "DELETE FROM " + table + " WHERE id = ? AND deleted_at IS NOT NULL"
+	return execInTrash(ctx, db, query, id)
+}
+
+func execInTrash(ctx context.Context, db *sql.DB, query string, id int) error {
+	result, err := db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNotInTrash
+	}
+	return nil
+}
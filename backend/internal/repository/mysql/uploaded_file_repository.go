@@ -0,0 +1,184 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"context"
+	"database/sql"
+)
+
+const uploadedFileColumns = "id, filename, original_filename, file_path, file_url, file_size, mime_type, uploaded_by, upload_context, is_used, created_at, updated_at"
+
+type uploadedFileRepository struct {
+	db *sql.DB
+}
+
+func NewUploadedFileRepository(db *sql.DB) repository.UploadedFileRepository {
+	return &uploadedFileRepository{db: db}
+}
+
+func scanUploadedFile(row rowScanner, f *entity.UploadedFile) error {
+	var uploadedBy, uploadContext sql.NullString
+	err := row.Scan(&f.ID, &f.Filename, &f.OriginalFilename, &f.FilePath, &f.FileURL, &f.FileSize,
+		&f.MimeType, &uploadedBy, &uploadContext, &f.IsUsed, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	f.UploadedBy = uploadedBy.String
+	f.UploadContext = uploadContext.String
+	return nil
+}
+
+func (r *uploadedFileRepository) query(ctx context.Context, query string, args ...interface{}) ([]entity.UploadedFile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []entity.UploadedFile
+	for rows.Next() {
+		var f entity.UploadedFile
+		if err := scanUploadedFile(rows, &f); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+func (r *uploadedFileRepository) getOne(ctx context.Context, query string, arg interface{}) (*entity.UploadedFile, error) {
+	var f entity.UploadedFile
+	err := scanUploadedFile(r.db.QueryRowContext(ctx, query, arg), &f)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func (r *uploadedFileRepository) Create(ctx context.Context, file *entity.UploadedFile) error {
+	query := `INSERT INTO uploaded_files (filename, original_filename, file_path, file_url, file_size, mime_type, uploaded_by, upload_context)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query, file.Filename, file.OriginalFilename, file.FilePath, file.FileURL,
+		file.FileSize, file.MimeType, file.UploadedBy, file.UploadContext)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	file.ID = int(id)
+	return nil
+}
+
+func (r *uploadedFileRepository) GetByID(ctx context.Context, id int) (*entity.UploadedFile, error) {
+	query := "SELECT " + uploadedFileColumns + " FROM uploaded_files WHERE id = ?"
+	return r.getOne(ctx, query, id)
+}
+
+func (r *uploadedFileRepository) GetByFilename(ctx context.Context, filename string) (*entity.UploadedFile, error) {
+	query := "SELECT " + uploadedFileColumns + " FROM uploaded_files WHERE filename = ? ORDER BY id DESC LIMIT 1"
+	return r.getOne(ctx, query, filename)
+}
+
+func (r *uploadedFileRepository) GetAll(ctx context.Context) ([]entity.UploadedFile, error) {
+	query := "SELECT " + uploadedFileColumns + " FROM uploaded_files ORDER BY created_at DESC"
+	return r.query(ctx, query)
+}
+
+func (r *uploadedFileRepository) GetByContext(ctx context.Context, uploadContext string) ([]entity.UploadedFile, error) {
+	query := "SELECT " + uploadedFileColumns + " FROM uploaded_files WHERE upload_context = ? ORDER BY created_at DESC"
+	return r.query(ctx, query, uploadContext)
+}
+
+func (r *uploadedFileRepository) GetUnused(ctx context.Context) ([]entity.UploadedFile, error) {
+	query := "SELECT " + uploadedFileColumns + " FROM uploaded_files WHERE is_used = FALSE ORDER BY created_at"
+	return r.query(ctx, query)
+}
+
+func (r *uploadedFileRepository) MarkAsUsed(ctx context.Context, id int) error {
+	query := "UPDATE uploaded_files SET is_used = TRUE WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *uploadedFileRepository) MarkAsUnused(ctx context.Context, id int) error {
+	query := "UPDATE uploaded_files SET is_used = FALSE WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *uploadedFileRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM uploaded_files WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *uploadedFileRepository) CreateFileUsage(ctx context.Context, usage *entity.FileUsage) error {
+	query := `INSERT INTO file_usage (uploaded_file_id, entity_type, entity_id, field_name) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`
+	result, err := r.db.ExecContext(ctx, query, usage.UploadedFileID, usage.EntityType, usage.EntityID, usage.FieldName)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	usage.ID = int(id)
+	return nil
+}
+
+func (r *uploadedFileRepository) DeleteFileUsage(ctx context.Context, uploadedFileID int, entityType string, entityID int) error {
+	query := "DELETE FROM file_usage WHERE uploaded_file_id = ? AND entity_type = ? AND entity_id = ?"
+	_, err := r.db.ExecContext(ctx, query, uploadedFileID, entityType, entityID)
+	return err
+}
+
+func (r *uploadedFileRepository) GetFileUsage(ctx context.Context, uploadedFileID int) ([]entity.FileUsage, error) {
+	query := "SELECT id, uploaded_file_id, entity_type, entity_id, field_name, created_at FROM file_usage WHERE uploaded_file_id = ?"
+	rows, err := r.db.QueryContext(ctx, query, uploadedFileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []entity.FileUsage
+	for rows.Next() {
+		var u entity.FileUsage
+		if err := rows.Scan(&u.ID, &u.UploadedFileID, &u.EntityType, &u.EntityID, &u.FieldName, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		usages = append(usages, u)
+	}
+
+	return usages, rows.Err()
+}
+
+func (r *uploadedFileRepository) IsReferenced(ctx context.Context, fileURL string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM banners WHERE image_url = ?)
+			OR EXISTS (SELECT 1 FROM struktur WHERE foto_url = ?)
+			OR EXISTS (SELECT 1 FROM pembina WHERE foto_url = ?)
+			OR EXISTS (SELECT 1 FROM qr_code WHERE image_url = ?)
+			OR EXISTS (SELECT 1 FROM kegiatan WHERE cover = ? OR deskripsi LIKE ?)
+			OR EXISTS (SELECT 1 FROM kegiatan_photos WHERE photo_url = ?)
+			OR EXISTS (SELECT 1 FROM kegiatan_foto WHERE image_url = ?)
+	`
+	inText := "%" + likeEscaper.Replace(fileURL) + "%"
+
+	var referenced bool
+	err := r.db.QueryRowContext(ctx, query, fileURL, fileURL, fileURL, fileURL, fileURL, inText, fileURL, fileURL).Scan(&referenced)
+	return referenced, err
+}
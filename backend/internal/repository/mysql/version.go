@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"arshaka-backend/internal/repository"
+	"context"
+)
+
+// Every editable row carries a version that goes up by one on each update. Update only applies
+// while the row is still at the version the entity was read with; version 0 skips the check so
+// older clients keep their last-write-wins behaviour.
+const (
+	bumpVersion  = "version = version + 1"
+	versionMatch = "(? = 0 OR version = ?)"
+)
+
+// execVersioned runs an UPDATE that bumps the version of the row with the given id and returns
+// the version it ended up with. It returns repository.ErrNoRowsAffected when nothing matched.
+func execVersioned(ctx context.Context, db querier, table string, id int, query string, args ...interface{}) (int, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, repository.ErrNoRowsAffected
+	}
+
+	var version int
+	err = db.QueryRowContext(ctx, "SELECT version FROM "+table+" WHERE id = ?", id).Scan(&version)
+	return version, err
+}
@@ -15,9 +15,12 @@ type UploadedFileRepository interface {
 	MarkAsUsed(ctx context.Context, id int) error
 	MarkAsUnused(ctx context.Context, id int) error
 	Delete(ctx context.Context, id int) error
-	
+
 	// File usage tracking
 	CreateFileUsage(ctx context.Context, usage *entity.FileUsage) error
 	DeleteFileUsage(ctx context.Context, uploadedFileID int, entityType string, entityID int) error
 	GetFileUsage(ctx context.Context, uploadedFileID int) ([]entity.FileUsage, error)
+
+	// IsReferenced reports whether any row, trashed or not, still points at the file URL
+	IsReferenced(ctx context.Context, fileURL string) (bool, error)
 }
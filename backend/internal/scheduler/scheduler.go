@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// JobFunc is a unit of background work run periodically by the Scheduler.
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// ObserveFunc is told how each job run went.
+type ObserveFunc func(name string, took time.Duration, err error)
+
+// Scheduler runs registered jobs on fixed intervals until it is stopped.
+type Scheduler struct {
+	jobs    []job
+	observe ObserveFunc
+	// stop ends the schedule while cancel also aborts runs in progress
+	stop     chan struct{}
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job. It must be called before Start.
+func (s *Scheduler) Add(name string, interval time.Duration, run JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Observe sets a function called after every job run, e.g. to record metrics. It must be called
+// before Start.
+func (s *Scheduler) Observe(fn ObserveFunc) {
+	s.observe = fn
+}
+
+// Start launches every registered job. Each job runs once immediately and then on its interval.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.stop = make(chan struct{})
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop ends the schedule so no job runs again. Runs in progress get until ctx is done to finish
+// and are cancelled after that; Stop returns once every job has returned.
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+	}
+	s.cancel()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		err := j.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "job failed", "job", j.name, "error", err)
+		}
+		if s.observe != nil {
+			s.observe(j.name, time.Since(start), err)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,32 +1,43 @@
 package usecase
 
 import (
+	"arshaka-backend/internal/apperror"
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"context"
-	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidCredentials = apperror.New(apperror.KindUnauthorized, "invalid_credentials")
+)
+
 type AuthUsecase interface {
 	Login(ctx context.Context, req *entity.LoginRequest) (*entity.LoginResponse, error)
 }
 
 type authUsecase struct {
-	adminRepo repository.AdminRepository
+	adminRepo      repository.AdminRepository
+	jwtSecret      []byte
+	sessionTimeout time.Duration
 }
 
-func NewAuthUsecase(adminRepo repository.AdminRepository) AuthUsecase {
+// NewAuthUsecase signs login tokens with jwtSecret; they expire after sessionTimeout.
+func NewAuthUsecase(adminRepo repository.AdminRepository, jwtSecret string, sessionTimeout time.Duration) AuthUsecase {
 	return &authUsecase{
-		adminRepo: adminRepo,
+		adminRepo:      adminRepo,
+		jwtSecret:      []byte(jwtSecret),
+		sessionTimeout: sessionTimeout,
 	}
 }
 
 func (u *authUsecase) Login(ctx context.Context, req *entity.LoginRequest) (*entity.LoginResponse, error) {
+	ctx, span := startSpan(ctx, "AuthUsecase.Login")
+	defer span.End()
+
 	// Get admin by username
 	admin, err := u.adminRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
@@ -34,13 +45,13 @@ func (u *authUsecase) Login(ctx context.Context, req *entity.LoginRequest) (*ent
 	}
 
 	if admin == nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	// Generate JWT token
@@ -59,16 +70,11 @@ func (u *authUsecase) generateToken(admin *entity.AdminUser) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":  admin.ID,
 		"username": admin.Username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // 24 hours
+		"exp":      time.Now().Add(u.sessionTimeout).Unix(),
 		"iat":      time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-super-secret-jwt-key-here"
-	}
-
-	return token.SignedString([]byte(secret))
+	return token.SignedString(u.jwtSecret)
 }
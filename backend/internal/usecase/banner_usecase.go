@@ -4,6 +4,8 @@ import (
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type BannerUsecase interface {
@@ -16,30 +18,59 @@ type BannerUsecase interface {
 
 type bannerUsecase struct {
 	bannerRepo repository.BannerRepository
+	cache      CacheInvalidator
 }
 
-func NewBannerUsecase(bannerRepo repository.BannerRepository) BannerUsecase {
+func NewBannerUsecase(bannerRepo repository.BannerRepository, cache CacheInvalidator) BannerUsecase {
 	return &bannerUsecase{
 		bannerRepo: bannerRepo,
+		cache:      cache,
 	}
 }
 
 func (u *bannerUsecase) GetAll(ctx context.Context) ([]entity.Banner, error) {
+	ctx, span := startSpan(ctx, "BannerUsecase.GetAll")
+	defer span.End()
+
 	return u.bannerRepo.GetAll(ctx)
 }
 
 func (u *bannerUsecase) GetByID(ctx context.Context, id int) (*entity.Banner, error) {
+	ctx, span := startSpan(ctx, "BannerUsecase.GetByID", attribute.Int("banner.id", id))
+	defer span.End()
+
 	return u.bannerRepo.GetByID(ctx, id)
 }
 
 func (u *bannerUsecase) Create(ctx context.Context, banner *entity.Banner) error {
-	return u.bannerRepo.Create(ctx, banner)
+	ctx, span := startSpan(ctx, "BannerUsecase.Create")
+	defer span.End()
+
+	if err := u.bannerRepo.Create(ctx, banner); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupBanners)
+	return nil
 }
 
 func (u *bannerUsecase) Update(ctx context.Context, banner *entity.Banner) error {
-	return u.bannerRepo.Update(ctx, banner)
+	ctx, span := startSpan(ctx, "BannerUsecase.Update", attribute.Int("banner.id", banner.ID))
+	defer span.End()
+
+	if err := u.bannerRepo.Update(ctx, banner); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupBanners)
+	return nil
 }
 
 func (u *bannerUsecase) Delete(ctx context.Context, id int) error {
-	return u.bannerRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "BannerUsecase.Delete", attribute.Int("banner.id", id))
+	defer span.End()
+
+	if err := u.bannerRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupBanners)
+	return nil
 }
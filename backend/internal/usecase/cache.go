@@ -0,0 +1,22 @@
+package usecase
+
+// Groups of cached public responses. After a change a usecase invalidates every group whose
+// responses show the changed data.
+const (
+	CacheGroupBanners    = "banners"
+	CacheGroupKegiatan   = "kegiatan"
+	CacheGroupStruktur   = "struktur"
+	CacheGroupPembina    = "pembina"
+	CacheGroupQRCode     = "qrcode"
+	CacheGroupCategories = "categories"
+	CacheGroupTags       = "tags"
+)
+
+// kegiatanCacheGroups show kegiatan: their lists and details, and the category counts and tags
+// listed next to them.
+var kegiatanCacheGroups = []string{CacheGroupKegiatan, CacheGroupCategories, CacheGroupTags}
+
+// CacheInvalidator drops cached responses once the data behind them changed.
+type CacheInvalidator interface {
+	Invalidate(groups ...string)
+}
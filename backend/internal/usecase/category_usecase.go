@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/pkg/slug"
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrCategoryNameRequired = apperror.New(apperror.KindValidation, "category_name_required")
+)
+
+type CategoryUsecase interface {
+	GetAll(ctx context.Context, publishedOnly bool) ([]entity.Category, error)
+	GetByID(ctx context.Context, id int) (*entity.Category, error)
+	Create(ctx context.Context, category *entity.Category) error
+	Update(ctx context.Context, category *entity.Category) error
+	Delete(ctx context.Context, id int) error
+}
+
+type categoryUsecase struct {
+	categoryRepo repository.CategoryRepository
+	cache        CacheInvalidator
+}
+
+func NewCategoryUsecase(categoryRepo repository.CategoryRepository, cache CacheInvalidator) CategoryUsecase {
+	return &categoryUsecase{
+		categoryRepo: categoryRepo,
+		cache:        cache,
+	}
+}
+
+func (u *categoryUsecase) GetAll(ctx context.Context, publishedOnly bool) ([]entity.Category, error) {
+	ctx, span := startSpan(ctx, "CategoryUsecase.GetAll", attribute.Bool("published_only", publishedOnly))
+	defer span.End()
+
+	return u.categoryRepo.GetAll(ctx, publishedOnly)
+}
+
+func (u *categoryUsecase) GetByID(ctx context.Context, id int) (*entity.Category, error) {
+	ctx, span := startSpan(ctx, "CategoryUsecase.GetByID", attribute.Int("category.id", id))
+	defer span.End()
+
+	return u.categoryRepo.GetByID(ctx, id)
+}
+
+func (u *categoryUsecase) Create(ctx context.Context, category *entity.Category) error {
+	ctx, span := startSpan(ctx, "CategoryUsecase.Create")
+	defer span.End()
+
+	if err := prepareCategory(category); err != nil {
+		return err
+	}
+	if err := u.categoryRepo.Create(ctx, category); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+func (u *categoryUsecase) Update(ctx context.Context, category *entity.Category) error {
+	ctx, span := startSpan(ctx, "CategoryUsecase.Update", attribute.Int("category.id", category.ID))
+	defer span.End()
+
+	if err := prepareCategory(category); err != nil {
+		return err
+	}
+	if err := u.categoryRepo.Update(ctx, category); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+func (u *categoryUsecase) Delete(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "CategoryUsecase.Delete", attribute.Int("category.id", id))
+	defer span.End()
+
+	if err := u.categoryRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+// prepareCategory trims the name and derives the slug when the admin did not set one.
+func prepareCategory(category *entity.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrCategoryNameRequired
+	}
+
+	category.Slug = slug.Make(category.Slug)
+	if category.Slug == "" {
+		category.Slug = slug.Make(category.Name)
+	}
+	if category.Slug == "" {
+		return ErrCategoryNameRequired
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+type HealthUsecase interface {
+	// Ready runs every readiness check, reporting each with its latency
+	Ready(ctx context.Context) entity.Readiness
+}
+
+type healthCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// checkFailure is a failed check. Code is all /readyz shows; the wrapped error, which may name
+// hosts and paths, is only logged.
+type checkFailure struct {
+	code string
+	err  error
+}
+
+func (f *checkFailure) Error() string {
+	return f.err.Error()
+}
+
+func (f *checkFailure) Unwrap() error {
+	return f.err
+}
+
+type healthUsecase struct {
+	schemaRepo repository.SchemaRepository
+	uploadsDir string
+}
+
+// NewHealthUsecase checks the dependencies the server needs to answer requests: the database,
+// the uploads directory and the schema.
+func NewHealthUsecase(schemaRepo repository.SchemaRepository, uploadsDir string) HealthUsecase {
+	return &healthUsecase{
+		schemaRepo: schemaRepo,
+		uploadsDir: uploadsDir,
+	}
+}
+
+func (u *healthUsecase) Ready(ctx context.Context) entity.Readiness {
+	checks := []healthCheck{
+		{"database", u.checkDatabase},
+		{"uploads", u.checkUploads},
+		{"migrations", u.checkMigrations},
+	}
+
+	readiness := entity.Readiness{Status: entity.HealthStatusOK}
+	for _, check := range checks {
+		start := time.Now()
+		detail, err := check.run(ctx)
+		result := entity.HealthCheck{
+			Name:      check.name,
+			Status:    entity.HealthStatusOK,
+			Detail:    detail,
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			result.Status = entity.HealthStatusFail
+			result.Detail = "failed"
+			var failure *checkFailure
+			if errors.As(err, &failure) {
+				result.Detail = failure.code
+			}
+			readiness.Status = entity.HealthStatusFail
+			slog.WarnContext(ctx, "readiness check failed", "check", check.name, "error", err)
+		}
+		readiness.Checks = append(readiness.Checks, result)
+	}
+	return readiness
+}
+
+func (u *healthUsecase) checkDatabase(ctx context.Context) (string, error) {
+	if err := u.schemaRepo.Ping(ctx); err != nil {
+		return "", &checkFailure{"unreachable", fmt.Errorf("ping failed: %w", err)}
+	}
+	return "", nil
+}
+
+// checkUploads creates and removes a file, since a read-only mount still passes a stat.
+func (u *healthUsecase) checkUploads(ctx context.Context) (string, error) {
+	f, err := os.CreateTemp(u.uploadsDir, ".readyz-*")
+	if err != nil {
+		return "", &checkFailure{"not_writable", fmt.Errorf("%s is not writable: %w", u.uploadsDir, err)}
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return "", &checkFailure{"not_writable", fmt.Errorf("%s: %w", u.uploadsDir, err)}
+	}
+	return "", nil
+}
+
+func (u *healthUsecase) checkMigrations(ctx context.Context) (string, error) {
+	applied, err := u.schemaRepo.AppliedMigrations(ctx)
+	if err != nil {
+		return "", &checkFailure{"unreadable", fmt.Errorf("cannot read schema_migrations: %w", err)}
+	}
+	if pending := migrations.Pending(applied); len(pending) > 0 {
+		return "", &checkFailure{"pending", fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))}
+	}
+	return fmt.Sprintf("schema version %d", migrations.SchemaVersion()), nil
+}
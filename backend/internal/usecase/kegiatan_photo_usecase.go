@@ -4,10 +4,13 @@ import (
 	"context"
 
 	"arshaka-backend/internal/entity"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type KegiatanPhotoUsecase interface {
 	GetByKegiatanID(ctx context.Context, kegiatanID int) ([]entity.KegiatanFoto, error)
+	GetByID(ctx context.Context, id int) (*entity.KegiatanFoto, error)
 	Create(ctx context.Context, photo *entity.KegiatanFoto) error
 	Update(ctx context.Context, photo *entity.KegiatanFoto) error
 	Delete(ctx context.Context, id int) error
@@ -24,38 +27,80 @@ type KegiatanPhotoRepository interface {
 	Delete(ctx context.Context, id int) error
 	UpdateSortOrder(ctx context.Context, photoID int, sortOrder int) error
 	GetByID(ctx context.Context, id int) (*entity.KegiatanFoto, error)
+	GetDeleted(ctx context.Context) ([]entity.KegiatanFoto, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 type kegiatanPhotoUsecase struct {
 	kegiatanPhotoRepo KegiatanPhotoRepository
+	cache             CacheInvalidator
 }
 
-func NewKegiatanPhotoUsecase(kegiatanPhotoRepo KegiatanPhotoRepository) KegiatanPhotoUsecase {
+func NewKegiatanPhotoUsecase(kegiatanPhotoRepo KegiatanPhotoRepository, cache CacheInvalidator) KegiatanPhotoUsecase {
 	return &kegiatanPhotoUsecase{
 		kegiatanPhotoRepo: kegiatanPhotoRepo,
+		cache:             cache,
 	}
 }
 
 func (u *kegiatanPhotoUsecase) GetByKegiatanID(ctx context.Context, kegiatanID int) ([]entity.KegiatanFoto, error) {
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.GetByKegiatanID", attribute.Int("kegiatan.id", kegiatanID))
+	defer span.End()
+
 	return u.kegiatanPhotoRepo.GetByKegiatanID(ctx, kegiatanID)
 }
 
+func (u *kegiatanPhotoUsecase) GetByID(ctx context.Context, id int) (*entity.KegiatanFoto, error) {
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.GetByID", attribute.Int("photo.id", id))
+	defer span.End()
+
+	return u.kegiatanPhotoRepo.GetByID(ctx, id)
+}
+
 func (u *kegiatanPhotoUsecase) Create(ctx context.Context, photo *entity.KegiatanFoto) error {
-	return u.kegiatanPhotoRepo.Create(ctx, photo)
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.Create", attribute.Int("kegiatan.id", photo.KegiatanID))
+	defer span.End()
+
+	if err := u.kegiatanPhotoRepo.Create(ctx, photo); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupKegiatan)
+	return nil
 }
 
 func (u *kegiatanPhotoUsecase) Update(ctx context.Context, photo *entity.KegiatanFoto) error {
-	return u.kegiatanPhotoRepo.Update(ctx, photo)
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.Update", attribute.Int("photo.id", photo.ID))
+	defer span.End()
+
+	if err := u.kegiatanPhotoRepo.Update(ctx, photo); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupKegiatan)
+	return nil
 }
 
 func (u *kegiatanPhotoUsecase) Delete(ctx context.Context, id int) error {
-	return u.kegiatanPhotoRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.Delete", attribute.Int("photo.id", id))
+	defer span.End()
+
+	if err := u.kegiatanPhotoRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupKegiatan)
+	return nil
 }
 
 func (u *kegiatanPhotoUsecase) UpdateSortOrder(ctx context.Context, photos []struct {
 	ID        int `json:"id"`
 	SortOrder int `json:"sort_order"`
 }) error {
+	ctx, span := startSpan(ctx, "KegiatanPhotoUsecase.UpdateSortOrder", attribute.Int("photos", len(photos)))
+	defer span.End()
+
+	// Photos sorted before a failure are saved too
+	defer u.cache.Invalidate(CacheGroupKegiatan)
+
 	for _, photo := range photos {
 		err := u.kegiatanPhotoRepo.UpdateSortOrder(ctx, photo.ID, photo.SortOrder)
 		if err != nil {
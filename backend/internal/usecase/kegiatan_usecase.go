@@ -1,54 +1,690 @@
 package usecase
 
 import (
+	"arshaka-backend/internal/apperror"
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
+	"arshaka-backend/pkg/markdown"
+	"arshaka-backend/pkg/slug"
 	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrInvalidKegiatanStatus = apperror.New(apperror.KindValidation, "kegiatan_status_invalid")
+	ErrInvalidEventTime      = apperror.New(apperror.KindValidation, "event_time_invalid")
+	ErrInvalidTimezone       = apperror.New(apperror.KindValidation, "timezone_invalid")
+	ErrInvalidCoordinates    = apperror.New(apperror.KindValidation, "coordinates_invalid")
 )
 
 type KegiatanUsecase interface {
-	GetAll(ctx context.Context) ([]entity.Kegiatan, error)
+	GetAll(ctx context.Context, filter entity.KegiatanFilter) ([]entity.Kegiatan, error)
+	GetUpcoming(ctx context.Context, limit int) ([]entity.Kegiatan, error)
 	GetByID(ctx context.Context, id int) (*entity.Kegiatan, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Kegiatan, error)
+	ResolveOldSlug(ctx context.Context, oldSlug string) (string, error)
+	GenerateMissingSlugs(ctx context.Context) (int, error)
 	Create(ctx context.Context, kegiatan *entity.Kegiatan) error
 	Update(ctx context.Context, kegiatan *entity.Kegiatan) error
 	Delete(ctx context.Context, id int) error
 	AddFoto(ctx context.Context, kegiatanID int, imageURL string) error
+	PublishScheduled(ctx context.Context) (int, error)
+	InvalidateStateChanges(ctx context.Context) (int, error)
+	GetRevisions(ctx context.Context, id int) ([]entity.Revision, error)
+	DiffRevisions(ctx context.Context, id, fromVersion, toVersion int) (*entity.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, id, version, currentVersion int) error
 }
 
 type kegiatanUsecase struct {
-	kegiatanRepo repository.KegiatanRepository
+	kegiatanRepo     repository.KegiatanRepository
+	tagRepo          repository.TagRepository
+	uploadedFileRepo repository.UploadedFileRepository
+	revisions        revisionLog
+	cache            CacheInvalidator
+
+	// checkedAt is when InvalidateStateChanges last looked for kegiatan that changed state
+	checkedMu sync.Mutex
+	checkedAt time.Time
 }
 
-func NewKegiatanUsecase(kegiatanRepo repository.KegiatanRepository) KegiatanUsecase {
+func NewKegiatanUsecase(kegiatanRepo repository.KegiatanRepository, tagRepo repository.TagRepository, uploadedFileRepo repository.UploadedFileRepository, revisionRepo repository.RevisionRepository, cache CacheInvalidator) KegiatanUsecase {
 	return &kegiatanUsecase{
-		kegiatanRepo: kegiatanRepo,
+		kegiatanRepo:     kegiatanRepo,
+		tagRepo:          tagRepo,
+		uploadedFileRepo: uploadedFileRepo,
+		revisions:        revisionLog{repo: revisionRepo, entityType: revisionTypeKegiatan},
+		cache:            cache,
+		checkedAt:        time.Now(),
+	}
+}
+
+// present fills the fields derived at read time.
+func present(kegiatan *entity.Kegiatan, now time.Time) {
+	kegiatan.State = kegiatan.EventState(now)
+
+	// Kegiatan saved before rich-text descriptions have no rendering yet
+	if kegiatan.DeskripsiHTML == "" && kegiatan.Deskripsi != "" {
+		kegiatan.DeskripsiHTML = markdown.ToHTML(kegiatan.Deskripsi, markdown.Options{})
+	}
+}
+
+func (u *kegiatanUsecase) GetAll(ctx context.Context, filter entity.KegiatanFilter) ([]entity.Kegiatan, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GetAll", attribute.Bool("published_only", filter.PublishedOnly),
+		attribute.String("category", filter.CategorySlug), attribute.String("tag", filter.TagSlug))
+	defer span.End()
+
+	kegiatan, err := u.kegiatanRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
+
+	now := time.Now()
+	for i := range kegiatan {
+		present(&kegiatan[i], now)
+	}
+	return kegiatan, nil
 }
 
-func (u *kegiatanUsecase) GetAll(ctx context.Context) ([]entity.Kegiatan, error) {
-	return u.kegiatanRepo.GetAll(ctx)
+// GetUpcoming returns published kegiatan that have not ended yet, soonest first.
+// A limit of zero or less returns all of them.
+func (u *kegiatanUsecase) GetUpcoming(ctx context.Context, limit int) ([]entity.Kegiatan, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GetUpcoming", attribute.Int("limit", limit))
+	defer span.End()
+
+	now := time.Now()
+	kegiatan, err := u.GetAll(ctx, entity.KegiatanFilter{PublishedOnly: true, EndsAfter: now})
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := []entity.Kegiatan{}
+	for _, k := range kegiatan {
+		if k.State != entity.KegiatanStatePast {
+			upcoming = append(upcoming, k)
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].StartAt.Before(upcoming[j].StartAt)
+	})
+	if limit > 0 && len(upcoming) > limit {
+		upcoming = upcoming[:limit]
+	}
+
+	return upcoming, nil
 }
 
 func (u *kegiatanUsecase) GetByID(ctx context.Context, id int) (*entity.Kegiatan, error) {
-	return u.kegiatanRepo.GetByID(ctx, id)
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GetByID", attribute.Int("kegiatan.id", id))
+	defer span.End()
+
+	kegiatan, err := u.kegiatanRepo.GetByID(ctx, id)
+	if kegiatan != nil {
+		present(kegiatan, time.Now())
+	}
+	return kegiatan, err
+}
+
+func (u *kegiatanUsecase) GetBySlug(ctx context.Context, slug string) (*entity.Kegiatan, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GetBySlug", attribute.String("kegiatan.slug", slug))
+	defer span.End()
+
+	kegiatan, err := u.kegiatanRepo.GetBySlug(ctx, slug)
+	if kegiatan != nil {
+		present(kegiatan, time.Now())
+	}
+	return kegiatan, err
+}
+
+// ResolveOldSlug returns the current slug for a slug the kegiatan had before being renamed.
+func (u *kegiatanUsecase) ResolveOldSlug(ctx context.Context, oldSlug string) (string, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.ResolveOldSlug", attribute.String("kegiatan.slug", oldSlug))
+	defer span.End()
+
+	return u.kegiatanRepo.GetSlugRedirect(ctx, oldSlug)
+}
+
+// GenerateMissingSlugs assigns a slug to every kegiatan created before slugs existed.
+func (u *kegiatanUsecase) GenerateMissingSlugs(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GenerateMissingSlugs")
+	defer span.End()
+
+	kegiatan, err := u.kegiatanRepo.GetWithoutSlug(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range kegiatan {
+		newSlug, err := u.uniqueSlug(ctx, &kegiatan[i])
+		if err != nil {
+			return i, err
+		}
+		if err := u.kegiatanRepo.UpdateSlug(ctx, kegiatan[i].ID, newSlug); err != nil {
+			return i, err
+		}
+	}
+
+	return len(kegiatan), nil
 }
 
 func (u *kegiatanUsecase) Create(ctx context.Context, kegiatan *entity.Kegiatan) error {
-	return u.kegiatanRepo.Create(ctx, kegiatan)
+	ctx, span := startSpan(ctx, "KegiatanUsecase.Create")
+	defer span.End()
+
+	// New kegiatan stay hidden until an admin publishes them
+	if kegiatan.Status == "" {
+		kegiatan.Status = entity.KegiatanStatusDraft
+	}
+	if !entity.IsValidKegiatanStatus(kegiatan.Status) {
+		return ErrInvalidKegiatanStatus
+	}
+	if err := normalizeSchedule(kegiatan, nil); err != nil {
+		return err
+	}
+
+	images, err := u.renderDeskripsi(ctx, kegiatan)
+	if err != nil {
+		return err
+	}
+
+	newSlug, err := u.uniqueSlug(ctx, kegiatan)
+	if err != nil {
+		return err
+	}
+	kegiatan.Slug = newSlug
+
+	// The repository links categories and tags in the same transaction, so tags are created first
+	if kegiatan.Tags != nil {
+		if kegiatan.Tags, err = resolveTags(ctx, u.tagRepo, kegiatan.Tags); err != nil {
+			return err
+		}
+	}
+	if err := u.kegiatanRepo.Create(ctx, kegiatan); err != nil {
+		return err
+	}
+	// Invalidate once images and revisions are saved too, even when one of those steps fails
+	defer u.cache.Invalidate(kegiatanCacheGroups...)
+	if err := u.trackDeskripsiImages(ctx, kegiatan.ID, images, nil); err != nil {
+		return err
+	}
+	return u.recordRevision(ctx, kegiatan.ID)
 }
 
 func (u *kegiatanUsecase) Update(ctx context.Context, kegiatan *entity.Kegiatan) error {
-	return u.kegiatanRepo.Update(ctx, kegiatan)
+	ctx, span := startSpan(ctx, "KegiatanUsecase.Update", attribute.Int("kegiatan.id", kegiatan.ID))
+	defer span.End()
+
+	existing, err := u.kegiatanRepo.GetByID(ctx, kegiatan.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil || (kegiatan.Version != 0 && kegiatan.Version != existing.Version) {
+		return repository.ErrNoRowsAffected
+	}
+
+	// Keep the current status when the client does not send one
+	if kegiatan.Status == "" {
+		kegiatan.Status = existing.Status
+		if kegiatan.PublishAt == nil {
+			kegiatan.PublishAt = existing.PublishAt
+		}
+	}
+	if !entity.IsValidKegiatanStatus(kegiatan.Status) {
+		return ErrInvalidKegiatanStatus
+	}
+	if err := normalizeSchedule(kegiatan, existing); err != nil {
+		return err
+	}
+	if err := u.revisions.recordBaseline(ctx, existing.ID, newKegiatanSnapshot(existing)); err != nil {
+		return err
+	}
+	images, err := u.renderDeskripsi(ctx, kegiatan)
+	if err != nil {
+		return err
+	}
+
+	// The slug only follows the title; other edits must not break shared links
+	if existing.Slug != "" && existing.Judul == kegiatan.Judul {
+		kegiatan.Slug = existing.Slug
+	} else {
+		newSlug, err := u.uniqueSlug(ctx, kegiatan)
+		if err != nil {
+			return err
+		}
+		kegiatan.Slug = newSlug
+	}
+
+	if err := u.kegiatanRepo.Update(ctx, kegiatan); err != nil {
+		return err
+	}
+	// Invalidate once tags and slugs are saved too, even when one of those steps fails
+	defer u.cache.Invalidate(kegiatanCacheGroups...)
+	if err := u.saveTaxonomy(ctx, kegiatan); err != nil {
+		return err
+	}
+
+	previousImages, err := u.deskripsiImages(ctx, existing.Deskripsi)
+	if err != nil {
+		return err
+	}
+	if err := u.trackDeskripsiImages(ctx, kegiatan.ID, images, previousImages); err != nil {
+		return err
+	}
+	if err := u.recordRevision(ctx, kegiatan.ID); err != nil {
+		return err
+	}
+
+	if existing.Slug == "" || existing.Slug == kegiatan.Slug {
+		return nil
+	}
+
+	// Keep the old slug so links shared before the rename still resolve
+	if err := u.kegiatanRepo.AddSlugHistory(ctx, kegiatan.ID, existing.Slug); err != nil {
+		return err
+	}
+	return u.kegiatanRepo.DeleteSlugHistory(ctx, kegiatan.ID, kegiatan.Slug)
 }
 
 func (u *kegiatanUsecase) Delete(ctx context.Context, id int) error {
-	return u.kegiatanRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "KegiatanUsecase.Delete", attribute.Int("kegiatan.id", id))
+	defer span.End()
+
+	if err := u.kegiatanRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
 }
 
 func (u *kegiatanUsecase) AddFoto(ctx context.Context, kegiatanID int, imageURL string) error {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.AddFoto", attribute.Int("kegiatan.id", kegiatanID))
+	defer span.End()
+
 	foto := &entity.KegiatanFoto{
 		KegiatanID: kegiatanID,
 		ImageURL:   imageURL,
 	}
-	return u.kegiatanRepo.CreateFoto(ctx, foto)
+	if err := u.kegiatanRepo.CreateFoto(ctx, foto); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+// normalizeSchedule fills in the time range and timezone and validates the location.
+// Clients that only send tanggal get a full-day event; when tanggal is unchanged on update
+// the existing time range is kept.
+func normalizeSchedule(kegiatan *entity.Kegiatan, existing *entity.Kegiatan) error {
+	if kegiatan.Timezone == "" {
+		kegiatan.Timezone = entity.DefaultTimezone
+		if existing != nil && existing.Timezone != "" {
+			kegiatan.Timezone = existing.Timezone
+		}
+	}
+	loc, err := time.LoadLocation(kegiatan.Timezone)
+	if err != nil {
+		return ErrInvalidTimezone
+	}
+
+	if kegiatan.StartAt.IsZero() {
+		switch {
+		case existing != nil && (kegiatan.Tanggal.IsZero() || sameDate(existing.Tanggal, kegiatan.Tanggal)):
+			kegiatan.StartAt = existing.StartAt
+			if kegiatan.EndAt.IsZero() {
+				kegiatan.EndAt = existing.EndAt
+			}
+		case !kegiatan.Tanggal.IsZero():
+			y, m, d := kegiatan.Tanggal.Date()
+			kegiatan.StartAt = time.Date(y, m, d, 0, 0, 0, 0, loc)
+		default:
+			return ErrInvalidEventTime
+		}
+	}
+
+	kegiatan.StartAt = kegiatan.StartAt.In(loc)
+	if kegiatan.EndAt.IsZero() {
+		y, m, d := kegiatan.StartAt.Date()
+		kegiatan.EndAt = time.Date(y, m, d, 23, 59, 59, 0, loc)
+	}
+	kegiatan.EndAt = kegiatan.EndAt.In(loc)
+	if kegiatan.EndAt.Before(kegiatan.StartAt) {
+		return ErrInvalidEventTime
+	}
+
+	// tanggal stays in sync with the first day for older clients and ordering
+	y, m, d := kegiatan.StartAt.Date()
+	kegiatan.Tanggal = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	lat, lng := kegiatan.Location.Latitude, kegiatan.Location.Longitude
+	if (lat == nil) != (lng == nil) {
+		return ErrInvalidCoordinates
+	}
+	if lat != nil && (*lat < -90 || *lat > 90 || *lng < -180 || *lng > 180) {
+		return ErrInvalidCoordinates
+	}
+
+	return nil
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// saveTaxonomy links the categories and tags sent with the kegiatan. A nil list leaves the
+// current links untouched while an empty list clears them.
+func (u *kegiatanUsecase) saveTaxonomy(ctx context.Context, kegiatan *entity.Kegiatan) error {
+	if kegiatan.Categories != nil {
+		categoryIDs := make([]int, 0, len(kegiatan.Categories))
+		for _, c := range kegiatan.Categories {
+			categoryIDs = append(categoryIDs, c.ID)
+		}
+		if err := u.kegiatanRepo.SetCategories(ctx, kegiatan.ID, categoryIDs); err != nil {
+			return err
+		}
+	}
+
+	if kegiatan.Tags != nil {
+		tags, err := resolveTags(ctx, u.tagRepo, kegiatan.Tags)
+		if err != nil {
+			return err
+		}
+		tagIDs := make([]int, 0, len(tags))
+		for _, t := range tags {
+			tagIDs = append(tagIDs, t.ID)
+		}
+		if err := u.kegiatanRepo.SetTags(ctx, kegiatan.ID, tagIDs); err != nil {
+			return err
+		}
+		kegiatan.Tags = tags
+	}
+
+	return nil
+}
+
+// deskripsiImages returns the uploaded files referenced as inline images in a description.
+// Images pointing anywhere other than a tracked upload are ignored.
+func (u *kegiatanUsecase) deskripsiImages(ctx context.Context, deskripsi string) ([]entity.UploadedFile, error) {
+	var files []entity.UploadedFile
+	seen := make(map[int]bool)
+	for _, src := range markdown.ImageURLs(deskripsi) {
+		filename := uploadFilename(src)
+		if filename == "" {
+			continue
+		}
+
+		file, err := u.uploadedFileRepo.GetByFilename(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil && !seen[file.ID] {
+			seen[file.ID] = true
+			files = append(files, *file)
+		}
+	}
+	return files, nil
+}
+
+// uploadFilename returns the file name of an /uploads/ URL, or "" for any other URL.
+func uploadFilename(src string) string {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/uploads/") {
+		return ""
+	}
+	filename := strings.TrimPrefix(u.Path, "/uploads/")
+	if filename == "" || filename != path.Base(filename) {
+		return ""
+	}
+	return filename
+}
+
+// renderDeskripsi renders the Markdown description into DeskripsiHTML, embedding only
+// images that were uploaded through the admin panel.
+func (u *kegiatanUsecase) renderDeskripsi(ctx context.Context, kegiatan *entity.Kegiatan) ([]entity.UploadedFile, error) {
+	images, err := u.deskripsiImages(ctx, kegiatan.Deskripsi)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(images))
+	for _, f := range images {
+		allowed[f.Filename] = true
+	}
+	kegiatan.DeskripsiHTML = markdown.ToHTML(kegiatan.Deskripsi, markdown.Options{
+		AllowImage: func(src string) bool {
+			return allowed[uploadFilename(src)]
+		},
+	})
+	return images, nil
+}
+
+// trackDeskripsiImages records which uploads the description uses so they are not treated
+// as orphans, and releases the ones it no longer references.
+func (u *kegiatanUsecase) trackDeskripsiImages(ctx context.Context, kegiatanID int, images, previous []entity.UploadedFile) error {
+	current := make(map[int]bool, len(images))
+	for _, f := range images {
+		current[f.ID] = true
+		usage := &entity.FileUsage{
+			UploadedFileID: f.ID,
+			EntityType:     "kegiatan",
+			EntityID:       kegiatanID,
+			FieldName:      "deskripsi",
+		}
+		if err := u.uploadedFileRepo.CreateFileUsage(ctx, usage); err != nil {
+			return err
+		}
+		if err := u.uploadedFileRepo.MarkAsUsed(ctx, f.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range previous {
+		if current[f.ID] {
+			continue
+		}
+		if err := u.uploadedFileRepo.DeleteFileUsage(ctx, f.ID, "kegiatan", kegiatanID); err != nil {
+			return err
+		}
+		usages, err := u.uploadedFileRepo.GetFileUsage(ctx, f.ID)
+		if err != nil {
+			return err
+		}
+		if len(usages) == 0 {
+			if err := u.uploadedFileRepo.MarkAsUnused(ctx, f.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// kegiatanSnapshot holds the editable fields of a kegiatan as stored in its revisions.
+type kegiatanSnapshot struct {
+	Judul       string          `json:"judul"`
+	Deskripsi   string          `json:"deskripsi"`
+	Cover       string          `json:"cover"`
+	StartAt     time.Time       `json:"start_at"`
+	EndAt       time.Time       `json:"end_at"`
+	Timezone    string          `json:"timezone"`
+	Location    entity.Location `json:"location"`
+	Status      string          `json:"status"`
+	PublishAt   *time.Time      `json:"publish_at"`
+	CategoryIDs []int           `json:"category_ids"`
+	Tags        []string        `json:"tags"`
+}
+
+func newKegiatanSnapshot(k *entity.Kegiatan) kegiatanSnapshot {
+	snapshot := kegiatanSnapshot{
+		Judul:       k.Judul,
+		Deskripsi:   k.Deskripsi,
+		Cover:       k.Cover,
+		StartAt:     k.StartAt,
+		EndAt:       k.EndAt,
+		Timezone:    k.Timezone,
+		Location:    k.Location,
+		Status:      k.Status,
+		PublishAt:   k.PublishAt,
+		CategoryIDs: []int{},
+		Tags:        []string{},
+	}
+	for _, c := range k.Categories {
+		snapshot.CategoryIDs = append(snapshot.CategoryIDs, c.ID)
+	}
+	for _, t := range k.Tags {
+		snapshot.Tags = append(snapshot.Tags, t.Name)
+	}
+	sort.Ints(snapshot.CategoryIDs)
+	sort.Strings(snapshot.Tags)
+	return snapshot
+}
+
+// kegiatan turns the snapshot back into a full update of the kegiatan with the given ID.
+func (s kegiatanSnapshot) kegiatan(id int) *entity.Kegiatan {
+	k := &entity.Kegiatan{
+		ID:         id,
+		Judul:      s.Judul,
+		Deskripsi:  s.Deskripsi,
+		Cover:      s.Cover,
+		StartAt:    s.StartAt,
+		EndAt:      s.EndAt,
+		Timezone:   s.Timezone,
+		Location:   s.Location,
+		Status:     s.Status,
+		PublishAt:  s.PublishAt,
+		Categories: []entity.Category{},
+		Tags:       []entity.Tag{},
+	}
+	for _, id := range s.CategoryIDs {
+		k.Categories = append(k.Categories, entity.Category{ID: id})
+	}
+	for _, name := range s.Tags {
+		k.Tags = append(k.Tags, entity.Tag{Name: name})
+	}
+	return k
+}
+
+// recordRevision snapshots the kegiatan as it is now stored.
+func (u *kegiatanUsecase) recordRevision(ctx context.Context, id int) error {
+	saved, err := u.kegiatanRepo.GetByID(ctx, id)
+	if err != nil || saved == nil {
+		return err
+	}
+	return u.revisions.record(ctx, id, newKegiatanSnapshot(saved))
+}
+
+// GetRevisions lists the saved versions of a kegiatan, newest first.
+func (u *kegiatanUsecase) GetRevisions(ctx context.Context, id int) ([]entity.Revision, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.GetRevisions", attribute.Int("kegiatan.id", id))
+	defer span.End()
+
+	return u.revisions.list(ctx, id)
+}
+
+// DiffRevisions lists the fields that differ between two versions of a kegiatan.
+func (u *kegiatanUsecase) DiffRevisions(ctx context.Context, id, fromVersion, toVersion int) (*entity.RevisionDiff, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.DiffRevisions", attribute.Int("kegiatan.id", id),
+		attribute.Int("revision.from", fromVersion), attribute.Int("revision.to", toVersion))
+	defer span.End()
+
+	return u.revisions.diff(ctx, id, fromVersion, toVersion)
+}
+
+// RestoreRevision saves an earlier version of a kegiatan as its current state, as long as the
+// kegiatan is still at currentVersion. The restore itself becomes the newest revision, so it can
+// be undone the same way.
+func (u *kegiatanUsecase) RestoreRevision(ctx context.Context, id, version, currentVersion int) error {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.RestoreRevision", attribute.Int("kegiatan.id", id),
+		attribute.Int("revision.version", version))
+	defer span.End()
+
+	var snapshot kegiatanSnapshot
+	if err := u.revisions.load(ctx, id, version, &snapshot); err != nil {
+		return err
+	}
+	restored := snapshot.kegiatan(id)
+	restored.Version = currentVersion
+	return u.Update(ctx, restored)
+}
+
+// PublishScheduled publishes drafts whose publish_at has passed.
+func (u *kegiatanUsecase) PublishScheduled(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.PublishScheduled")
+	defer span.End()
+
+	published, err := u.kegiatanRepo.PublishDue(ctx, time.Now())
+	if published > 0 {
+		u.cache.Invalidate(kegiatanCacheGroups...)
+	}
+	return published, err
+}
+
+// InvalidateStateChanges drops the cached kegiatan output when a published kegiatan became
+// public, started or ended since the previous call. Public output depends on the clock, and no
+// edit invalidates it when one of those moments passes.
+func (u *kegiatanUsecase) InvalidateStateChanges(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "KegiatanUsecase.InvalidateStateChanges")
+	defer span.End()
+
+	u.checkedMu.Lock()
+	defer u.checkedMu.Unlock()
+
+	now := time.Now()
+	changed, err := u.kegiatanRepo.CountStateChanges(ctx, u.checkedAt, now)
+	if err != nil {
+		return 0, err
+	}
+	if changed > 0 {
+		u.cache.Invalidate(kegiatanCacheGroups...)
+	}
+	u.checkedAt = now
+	return changed, nil
+}
+
+// uniqueSlug derives a slug from the title, falling back to the kegiatan date and then a
+// counter when the plain slug already belongs to another kegiatan.
+func (u *kegiatanUsecase) uniqueSlug(ctx context.Context, kegiatan *entity.Kegiatan) (string, error) {
+	base := slug.Make(kegiatan.Judul)
+	if base == "" {
+		base = "kegiatan"
+	} else if _, err := strconv.Atoi(base); err == nil {
+		// A purely numeric slug would be mistaken for an ID in /kegiatan/{id} links
+		base = "kegiatan-" + base
+	}
+
+	candidates := []string{base}
+	if !kegiatan.Tanggal.IsZero() {
+		candidates = append(candidates, base+"-"+kegiatan.Tanggal.Format("2006-01-02"))
+	}
+
+	for _, candidate := range candidates {
+		taken, err := u.kegiatanRepo.SlugTaken(ctx, candidate, kegiatan.ID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	last := candidates[len(candidates)-1]
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", last, n)
+		taken, err := u.kegiatanRepo.SlugTaken(ctx, candidate, kegiatan.ID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
 }
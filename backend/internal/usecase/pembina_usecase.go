@@ -4,6 +4,8 @@ import (
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PembinaUsecase interface {
@@ -17,34 +19,66 @@ type PembinaUsecase interface {
 
 type pembinaUsecase struct {
 	pembinaRepo repository.PembinaRepository
+	cache       CacheInvalidator
 }
 
-func NewPembinaUsecase(pembinaRepo repository.PembinaRepository) PembinaUsecase {
+func NewPembinaUsecase(pembinaRepo repository.PembinaRepository, cache CacheInvalidator) PembinaUsecase {
 	return &pembinaUsecase{
 		pembinaRepo: pembinaRepo,
+		cache:       cache,
 	}
 }
 
 func (u *pembinaUsecase) GetAll(ctx context.Context) ([]entity.Pembina, error) {
+	ctx, span := startSpan(ctx, "PembinaUsecase.GetAll")
+	defer span.End()
+
 	return u.pembinaRepo.GetAll(ctx)
 }
 
 func (u *pembinaUsecase) GetByID(ctx context.Context, id int) (*entity.Pembina, error) {
+	ctx, span := startSpan(ctx, "PembinaUsecase.GetByID", attribute.Int("pembina.id", id))
+	defer span.End()
+
 	return u.pembinaRepo.GetByID(ctx, id)
 }
 
 func (u *pembinaUsecase) Create(ctx context.Context, pembina *entity.Pembina) error {
-	return u.pembinaRepo.Create(ctx, pembina)
+	ctx, span := startSpan(ctx, "PembinaUsecase.Create")
+	defer span.End()
+
+	if err := u.pembinaRepo.Create(ctx, pembina); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupPembina)
+	return nil
 }
 
 func (u *pembinaUsecase) Update(ctx context.Context, pembina *entity.Pembina) error {
-	return u.pembinaRepo.Update(ctx, pembina)
+	ctx, span := startSpan(ctx, "PembinaUsecase.Update", attribute.Int("pembina.id", pembina.ID))
+	defer span.End()
+
+	if err := u.pembinaRepo.Update(ctx, pembina); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupPembina)
+	return nil
 }
 
 func (u *pembinaUsecase) Delete(ctx context.Context, id int) error {
-	return u.pembinaRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "PembinaUsecase.Delete", attribute.Int("pembina.id", id))
+	defer span.End()
+
+	if err := u.pembinaRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupPembina)
+	return nil
 }
 
 func (u *pembinaUsecase) Count(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "PembinaUsecase.Count")
+	defer span.End()
+
 	return u.pembinaRepo.Count(ctx)
 }
@@ -4,6 +4,8 @@ import (
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type QRCodeUsecase interface {
@@ -18,38 +20,77 @@ type QRCodeUsecase interface {
 
 type qrcodeUsecase struct {
 	qrcodeRepo repository.QRCodeRepository
+	cache      CacheInvalidator
 }
 
-func NewQRCodeUsecase(qrcodeRepo repository.QRCodeRepository) QRCodeUsecase {
+func NewQRCodeUsecase(qrcodeRepo repository.QRCodeRepository, cache CacheInvalidator) QRCodeUsecase {
 	return &qrcodeUsecase{
 		qrcodeRepo: qrcodeRepo,
+		cache:      cache,
 	}
 }
 
 func (u *qrcodeUsecase) GetAll(ctx context.Context) ([]entity.QRCode, error) {
+	ctx, span := startSpan(ctx, "QRCodeUsecase.GetAll")
+	defer span.End()
+
 	return u.qrcodeRepo.GetAll(ctx)
 }
 
 func (u *qrcodeUsecase) GetEnabled(ctx context.Context) ([]entity.QRCode, error) {
+	ctx, span := startSpan(ctx, "QRCodeUsecase.GetEnabled")
+	defer span.End()
+
 	return u.qrcodeRepo.GetEnabled(ctx)
 }
 
 func (u *qrcodeUsecase) GetByID(ctx context.Context, id int) (*entity.QRCode, error) {
+	ctx, span := startSpan(ctx, "QRCodeUsecase.GetByID", attribute.Int("qrcode.id", id))
+	defer span.End()
+
 	return u.qrcodeRepo.GetByID(ctx, id)
 }
 
 func (u *qrcodeUsecase) Create(ctx context.Context, qrcode *entity.QRCode) error {
-	return u.qrcodeRepo.Create(ctx, qrcode)
+	ctx, span := startSpan(ctx, "QRCodeUsecase.Create")
+	defer span.End()
+
+	if err := u.qrcodeRepo.Create(ctx, qrcode); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupQRCode)
+	return nil
 }
 
 func (u *qrcodeUsecase) Update(ctx context.Context, qrcode *entity.QRCode) error {
-	return u.qrcodeRepo.Update(ctx, qrcode)
+	ctx, span := startSpan(ctx, "QRCodeUsecase.Update", attribute.Int("qrcode.id", qrcode.ID))
+	defer span.End()
+
+	if err := u.qrcodeRepo.Update(ctx, qrcode); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupQRCode)
+	return nil
 }
 
 func (u *qrcodeUsecase) Delete(ctx context.Context, id int) error {
-	return u.qrcodeRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "QRCodeUsecase.Delete", attribute.Int("qrcode.id", id))
+	defer span.End()
+
+	if err := u.qrcodeRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupQRCode)
+	return nil
 }
 
 func (u *qrcodeUsecase) ToggleEnable(ctx context.Context, id int) error {
-	return u.qrcodeRepo.ToggleEnable(ctx, id)
+	ctx, span := startSpan(ctx, "QRCodeUsecase.ToggleEnable", attribute.Int("qrcode.id", id))
+	defer span.End()
+
+	if err := u.qrcodeRepo.ToggleEnable(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupQRCode)
+	return nil
 }
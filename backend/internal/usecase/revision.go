@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+var (
+	ErrRevisionNotFound = apperror.New(apperror.KindNotFound, "revision_not_found")
+)
+
+// Entity types stored in the revisions table.
+const (
+	revisionTypeKegiatan = "kegiatan"
+	revisionTypeStruktur = "struktur"
+)
+
+// revisionLog keeps the revision history of one entity type.
+type revisionLog struct {
+	repo       repository.RevisionRepository
+	entityType string
+}
+
+// record stores snapshot as the newest revision of the entity.
+func (l revisionLog) record(ctx context.Context, entityID int, snapshot interface{}) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return l.repo.Create(ctx, &entity.Revision{
+		EntityType: l.entityType,
+		EntityID:   entityID,
+		Snapshot:   data,
+	})
+}
+
+// recordBaseline stores the current state of an entity that was created before revisions
+// existed, so its first edit can still be rolled back.
+func (l revisionLog) recordBaseline(ctx context.Context, entityID int, snapshot interface{}) error {
+	revisions, err := l.repo.GetByEntity(ctx, l.entityType, entityID)
+	if err != nil || len(revisions) > 0 {
+		return err
+	}
+	return l.record(ctx, entityID, snapshot)
+}
+
+func (l revisionLog) list(ctx context.Context, entityID int) ([]entity.Revision, error) {
+	return l.repo.GetByEntity(ctx, l.entityType, entityID)
+}
+
+// load decodes the snapshot of a revision into dst.
+func (l revisionLog) load(ctx context.Context, entityID, version int, dst interface{}) error {
+	revision, err := l.repo.GetByVersion(ctx, l.entityType, entityID, version)
+	if err != nil {
+		return err
+	}
+	if revision == nil {
+		return ErrRevisionNotFound
+	}
+	return json.Unmarshal(revision.Snapshot, dst)
+}
+
+// diff lists the fields that changed between two revisions.
+func (l revisionLog) diff(ctx context.Context, entityID, fromVersion, toVersion int) (*entity.RevisionDiff, error) {
+	var from, to map[string]interface{}
+	if err := l.load(ctx, entityID, fromVersion, &from); err != nil {
+		return nil, err
+	}
+	if err := l.load(ctx, entityID, toVersion, &to); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range from {
+		fields[field] = true
+	}
+	for field := range to {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := []entity.FieldChange{}
+	for _, field := range names {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes = append(changes, entity.FieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+
+	return &entity.RevisionDiff{
+		EntityType:  l.entityType,
+		EntityID:    entityID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+	}, nil
+}
@@ -4,6 +4,8 @@ import (
 	"arshaka-backend/internal/entity"
 	"arshaka-backend/internal/repository"
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type StrukturUsecase interface {
@@ -12,34 +14,167 @@ type StrukturUsecase interface {
 	Create(ctx context.Context, struktur *entity.Struktur) error
 	Update(ctx context.Context, struktur *entity.Struktur) error
 	Delete(ctx context.Context, id int) error
+	GetRevisions(ctx context.Context, id int) ([]entity.Revision, error)
+	DiffRevisions(ctx context.Context, id, fromVersion, toVersion int) (*entity.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, id, version, currentVersion int) error
 }
 
 type strukturUsecase struct {
 	strukturRepo repository.StrukturRepository
+	revisions    revisionLog
+	tx           repository.Transactor
+	cache        CacheInvalidator
 }
 
-func NewStrukturUsecase(strukturRepo repository.StrukturRepository) StrukturUsecase {
+func NewStrukturUsecase(strukturRepo repository.StrukturRepository, revisionRepo repository.RevisionRepository, tx repository.Transactor, cache CacheInvalidator) StrukturUsecase {
 	return &strukturUsecase{
 		strukturRepo: strukturRepo,
+		revisions:    revisionLog{repo: revisionRepo, entityType: revisionTypeStruktur},
+		tx:           tx,
+		cache:        cache,
+	}
+}
+
+// strukturSnapshot holds the editable fields of a struktur member as stored in its revisions.
+type strukturSnapshot struct {
+	Nama     string `json:"nama"`
+	Jabatan  string `json:"jabatan"`
+	Prodi    string `json:"prodi"`
+	Angkatan string `json:"angkatan"`
+	NRA      string `json:"nra"`
+	FotoURL  string `json:"foto_url"`
+}
+
+func newStrukturSnapshot(s *entity.Struktur) strukturSnapshot {
+	return strukturSnapshot{
+		Nama:     s.Nama,
+		Jabatan:  s.Jabatan,
+		Prodi:    s.Prodi,
+		Angkatan: s.Angkatan,
+		NRA:      s.NRA,
+		FotoURL:  s.FotoURL,
 	}
 }
 
 func (u *strukturUsecase) GetAll(ctx context.Context) ([]entity.Struktur, error) {
+	ctx, span := startSpan(ctx, "StrukturUsecase.GetAll")
+	defer span.End()
+
 	return u.strukturRepo.GetAll(ctx)
 }
 
 func (u *strukturUsecase) GetByID(ctx context.Context, id int) (*entity.Struktur, error) {
+	ctx, span := startSpan(ctx, "StrukturUsecase.GetByID", attribute.Int("struktur.id", id))
+	defer span.End()
+
 	return u.strukturRepo.GetByID(ctx, id)
 }
 
+// Create saves the member and its first revision in one transaction.
 func (u *strukturUsecase) Create(ctx context.Context, struktur *entity.Struktur) error {
-	return u.strukturRepo.Create(ctx, struktur)
+	ctx, span := startSpan(ctx, "StrukturUsecase.Create")
+	defer span.End()
+
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.strukturRepo.Create(ctx, struktur); err != nil {
+			return err
+		}
+		return u.recordRevision(ctx, struktur.ID)
+	})
+	if err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupStruktur)
+	return nil
 }
 
+// Update saves the member and the revision of the stored result in one transaction, so an edit
+// is never applied without its revision.
 func (u *strukturUsecase) Update(ctx context.Context, struktur *entity.Struktur) error {
-	return u.strukturRepo.Update(ctx, struktur)
+	ctx, span := startSpan(ctx, "StrukturUsecase.Update", attribute.Int("struktur.id", struktur.ID))
+	defer span.End()
+
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := u.strukturRepo.GetByID(ctx, struktur.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil || (struktur.Version != 0 && struktur.Version != existing.Version) {
+			return repository.ErrNoRowsAffected
+		}
+		if err := u.revisions.recordBaseline(ctx, existing.ID, newStrukturSnapshot(existing)); err != nil {
+			return err
+		}
+
+		if err := u.strukturRepo.Update(ctx, struktur); err != nil {
+			return err
+		}
+		return u.recordRevision(ctx, struktur.ID)
+	})
+	if err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupStruktur)
+	return nil
+}
+
+// recordRevision stores the member as saved, with what the database made of the input.
+func (u *strukturUsecase) recordRevision(ctx context.Context, id int) error {
+	saved, err := u.strukturRepo.GetByID(ctx, id)
+	if err != nil || saved == nil {
+		return err
+	}
+	return u.revisions.record(ctx, id, newStrukturSnapshot(saved))
 }
 
 func (u *strukturUsecase) Delete(ctx context.Context, id int) error {
-	return u.strukturRepo.Delete(ctx, id)
+	ctx, span := startSpan(ctx, "StrukturUsecase.Delete", attribute.Int("struktur.id", id))
+	defer span.End()
+
+	if err := u.strukturRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(CacheGroupStruktur)
+	return nil
+}
+
+// GetRevisions lists the saved versions of a struktur member, newest first.
+func (u *strukturUsecase) GetRevisions(ctx context.Context, id int) ([]entity.Revision, error) {
+	ctx, span := startSpan(ctx, "StrukturUsecase.GetRevisions", attribute.Int("struktur.id", id))
+	defer span.End()
+
+	return u.revisions.list(ctx, id)
+}
+
+// DiffRevisions lists the fields that differ between two versions of a struktur member.
+func (u *strukturUsecase) DiffRevisions(ctx context.Context, id, fromVersion, toVersion int) (*entity.RevisionDiff, error) {
+	ctx, span := startSpan(ctx, "StrukturUsecase.DiffRevisions", attribute.Int("struktur.id", id),
+		attribute.Int("revision.from", fromVersion), attribute.Int("revision.to", toVersion))
+	defer span.End()
+
+	return u.revisions.diff(ctx, id, fromVersion, toVersion)
+}
+
+// RestoreRevision saves an earlier version of a struktur member as its current state, as long as
+// the member is still at currentVersion.
+func (u *strukturUsecase) RestoreRevision(ctx context.Context, id, version, currentVersion int) error {
+	ctx, span := startSpan(ctx, "StrukturUsecase.RestoreRevision", attribute.Int("struktur.id", id),
+		attribute.Int("revision.version", version))
+	defer span.End()
+
+	var snapshot strukturSnapshot
+	if err := u.revisions.load(ctx, id, version, &snapshot); err != nil {
+		return err
+	}
+
+	return u.Update(ctx, &entity.Struktur{
+		ID:       id,
+		Version:  currentVersion,
+		Nama:     snapshot.Nama,
+		Jabatan:  snapshot.Jabatan,
+		Prodi:    snapshot.Prodi,
+		Angkatan: snapshot.Angkatan,
+		NRA:      snapshot.NRA,
+		FotoURL:  snapshot.FotoURL,
+	})
 }
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/pkg/slug"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tagMaxLength is the size of the name and slug columns of tags.
+const tagMaxLength = 50
+
+var (
+	ErrTagNameRequired = apperror.New(apperror.KindValidation, "tag_name_required")
+	ErrTagNameTooLong  = apperror.New(apperror.KindValidation, "tag_name_too_long")
+)
+
+type TagUsecase interface {
+	GetAll(ctx context.Context) ([]entity.Tag, error)
+	GetByID(ctx context.Context, id int) (*entity.Tag, error)
+	Create(ctx context.Context, tag *entity.Tag) error
+	Update(ctx context.Context, tag *entity.Tag) error
+	Delete(ctx context.Context, id int) error
+}
+
+type tagUsecase struct {
+	tagRepo repository.TagRepository
+	cache   CacheInvalidator
+}
+
+func NewTagUsecase(tagRepo repository.TagRepository, cache CacheInvalidator) TagUsecase {
+	return &tagUsecase{
+		tagRepo: tagRepo,
+		cache:   cache,
+	}
+}
+
+func (u *tagUsecase) GetAll(ctx context.Context) ([]entity.Tag, error) {
+	ctx, span := startSpan(ctx, "TagUsecase.GetAll")
+	defer span.End()
+
+	return u.tagRepo.GetAll(ctx)
+}
+
+func (u *tagUsecase) GetByID(ctx context.Context, id int) (*entity.Tag, error) {
+	ctx, span := startSpan(ctx, "TagUsecase.GetByID", attribute.Int("tag.id", id))
+	defer span.End()
+
+	return u.tagRepo.GetByID(ctx, id)
+}
+
+func (u *tagUsecase) Create(ctx context.Context, tag *entity.Tag) error {
+	ctx, span := startSpan(ctx, "TagUsecase.Create")
+	defer span.End()
+
+	if err := prepareTag(tag); err != nil {
+		return err
+	}
+	if err := u.tagRepo.Create(ctx, tag); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+func (u *tagUsecase) Update(ctx context.Context, tag *entity.Tag) error {
+	ctx, span := startSpan(ctx, "TagUsecase.Update", attribute.Int("tag.id", tag.ID))
+	defer span.End()
+
+	if err := prepareTag(tag); err != nil {
+		return err
+	}
+	if err := u.tagRepo.Update(ctx, tag); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+func (u *tagUsecase) Delete(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "TagUsecase.Delete", attribute.Int("tag.id", id))
+	defer span.End()
+
+	if err := u.tagRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.cache.Invalidate(kegiatanCacheGroups...)
+	return nil
+}
+
+func prepareTag(tag *entity.Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.Slug = slug.Make(tag.Name)
+	if tag.Slug == "" {
+		return ErrTagNameRequired
+	}
+	if utf8.RuneCountInString(tag.Name) > tagMaxLength || len(tag.Slug) > tagMaxLength {
+		return ErrTagNameTooLong
+	}
+	return nil
+}
+
+// resolveTags looks up free-form tags by slug, creating the ones that do not exist yet.
+func resolveTags(ctx context.Context, tagRepo repository.TagRepository, tags []entity.Tag) ([]entity.Tag, error) {
+	resolved := []entity.Tag{}
+	seen := make(map[int]bool)
+
+	for _, t := range tags {
+		if err := prepareTag(&t); err == ErrTagNameRequired {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		existing, err := tagRepo.GetBySlug(ctx, t.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			if err := tagRepo.Create(ctx, &t); err != nil && err != repository.ErrTagExists {
+				return nil, err
+			}
+			// Another request may have created the same tag in the meantime
+			if existing, err = tagRepo.GetBySlug(ctx, t.Slug); err != nil {
+				return nil, err
+			}
+		}
+
+		if existing != nil && !seen[existing.ID] {
+			seen[existing.ID] = true
+			resolved = append(resolved, *existing)
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/entity"
+	"arshaka-backend/internal/repository"
+	"arshaka-backend/pkg/markdown"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrInvalidTrashType = apperror.New(apperror.KindValidation, "trash_type_invalid")
+)
+
+type TrashUsecase interface {
+	GetAll(ctx context.Context) ([]entity.TrashItem, error)
+	Restore(ctx context.Context, itemType string, id int) error
+	Purge(ctx context.Context, itemType string, id int) error
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type trashUsecase struct {
+	kegiatanRepo      repository.KegiatanRepository
+	kegiatanPhotoRepo KegiatanPhotoRepository
+	bannerRepo        repository.BannerRepository
+	strukturRepo      repository.StrukturRepository
+	pembinaRepo       repository.PembinaRepository
+	qrcodeRepo        repository.QRCodeRepository
+	uploadedFileRepo  repository.UploadedFileRepository
+	revisionRepo      repository.RevisionRepository
+	uploadsDir        string
+	retention         time.Duration
+	cache             CacheInvalidator
+}
+
+// NewTrashUsecase manages soft-deleted content. Trashed items are purged for good once they
+// have been in the trash for longer than retention, together with files nothing else uses.
+func NewTrashUsecase(
+	kegiatanRepo repository.KegiatanRepository,
+	kegiatanPhotoRepo KegiatanPhotoRepository,
+	bannerRepo repository.BannerRepository,
+	strukturRepo repository.StrukturRepository,
+	pembinaRepo repository.PembinaRepository,
+	qrcodeRepo repository.QRCodeRepository,
+	uploadedFileRepo repository.UploadedFileRepository,
+	revisionRepo repository.RevisionRepository,
+	uploadsDir string,
+	retention time.Duration,
+	cache CacheInvalidator,
+) TrashUsecase {
+	return &trashUsecase{
+		kegiatanRepo:      kegiatanRepo,
+		kegiatanPhotoRepo: kegiatanPhotoRepo,
+		bannerRepo:        bannerRepo,
+		strukturRepo:      strukturRepo,
+		pembinaRepo:       pembinaRepo,
+		qrcodeRepo:        qrcodeRepo,
+		uploadedFileRepo:  uploadedFileRepo,
+		revisionRepo:      revisionRepo,
+		uploadsDir:        uploadsDir,
+		retention:         retention,
+		cache:             cache,
+	}
+}
+
+// trashEntry pairs a trash item with the file URLs it references.
+type trashEntry struct {
+	item  entity.TrashItem
+	files []string
+}
+
+func (u *trashUsecase) GetAll(ctx context.Context) ([]entity.TrashItem, error) {
+	ctx, span := startSpan(ctx, "TrashUsecase.GetAll")
+	defer span.End()
+
+	entries, err := u.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]entity.TrashItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, e.item)
+	}
+	return items, nil
+}
+
+func (u *trashUsecase) Restore(ctx context.Context, itemType string, id int) error {
+	ctx, span := startSpan(ctx, "TrashUsecase.Restore", attribute.String("trash.type", itemType), attribute.Int("trash.id", id))
+	defer span.End()
+
+	var err error
+	var groups []string
+	switch itemType {
+	case entity.TrashTypeKegiatan:
+		err, groups = u.kegiatanRepo.Restore(ctx, id), kegiatanCacheGroups
+	case entity.TrashTypeKegiatanPhoto:
+		err, groups = u.kegiatanPhotoRepo.Restore(ctx, id), []string{CacheGroupKegiatan}
+	case entity.TrashTypeBanner:
+		err, groups = u.bannerRepo.Restore(ctx, id), []string{CacheGroupBanners}
+	case entity.TrashTypeStruktur:
+		err, groups = u.strukturRepo.Restore(ctx, id), []string{CacheGroupStruktur}
+	case entity.TrashTypePembina:
+		err, groups = u.pembinaRepo.Restore(ctx, id), []string{CacheGroupPembina}
+	case entity.TrashTypeQRCode:
+		err, groups = u.qrcodeRepo.Restore(ctx, id), []string{CacheGroupQRCode}
+	default:
+		return ErrInvalidTrashType
+	}
+	if err != nil {
+		return err
+	}
+	u.cache.Invalidate(groups...)
+	return nil
+}
+
+// Purge permanently deletes a single item from the trash without waiting for the retention period.
+func (u *trashUsecase) Purge(ctx context.Context, itemType string, id int) error {
+	ctx, span := startSpan(ctx, "TrashUsecase.Purge", attribute.String("trash.type", itemType), attribute.Int("trash.id", id))
+	defer span.End()
+
+	if !entity.IsValidTrashType(itemType) {
+		return ErrInvalidTrashType
+	}
+
+	entries, err := u.entries(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.item.Type == itemType && e.item.ID == id {
+			return u.purge(ctx, e)
+		}
+	}
+	return repository.ErrNotInTrash
+}
+
+// PurgeExpired permanently deletes every item that has been in the trash longer than the
+// retention period and returns how many were removed.
+func (u *trashUsecase) PurgeExpired(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "TrashUsecase.PurgeExpired")
+	defer span.End()
+
+	entries, err := u.entries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	for _, e := range entries {
+		if e.item.PurgeAt.After(now) {
+			continue
+		}
+		if err := u.purge(ctx, e); err != nil {
+			// A trashed photo is already gone when its kegiatan was purged before it
+			if errors.Is(err, repository.ErrNotInTrash) {
+				continue
+			}
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (u *trashUsecase) purge(ctx context.Context, e trashEntry) error {
+	var err error
+	switch e.item.Type {
+	case entity.TrashTypeKegiatan:
+		err = u.kegiatanRepo.Purge(ctx, e.item.ID)
+	case entity.TrashTypeKegiatanPhoto:
+		err = u.kegiatanPhotoRepo.Purge(ctx, e.item.ID)
+	case entity.TrashTypeBanner:
+		err = u.bannerRepo.Purge(ctx, e.item.ID)
+	case entity.TrashTypeStruktur:
+		err = u.strukturRepo.Purge(ctx, e.item.ID)
+	case entity.TrashTypePembina:
+		err = u.pembinaRepo.Purge(ctx, e.item.ID)
+	case entity.TrashTypeQRCode:
+		err = u.qrcodeRepo.Purge(ctx, e.item.ID)
+	default:
+		err = ErrInvalidTrashType
+	}
+	if err != nil {
+		return err
+	}
+
+	// Revision history has nothing left to restore into
+	switch e.item.Type {
+	case entity.TrashTypeKegiatan:
+		err = u.revisionRepo.DeleteByEntity(ctx, revisionTypeKegiatan, e.item.ID)
+	case entity.TrashTypeStruktur:
+		err = u.revisionRepo.DeleteByEntity(ctx, revisionTypeStruktur, e.item.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, fileURL := range e.files {
+		if err := u.removeUnusedFile(ctx, fileURL, e.item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUnusedFile deletes an uploaded file once no remaining row points at it.
+func (u *trashUsecase) removeUnusedFile(ctx context.Context, fileURL string, item entity.TrashItem) error {
+	filename := uploadFilename(fileURL)
+	if filename == "" {
+		return nil
+	}
+
+	file, err := u.uploadedFileRepo.GetByFilename(ctx, filename)
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		if err := u.uploadedFileRepo.DeleteFileUsage(ctx, file.ID, item.Type, item.ID); err != nil {
+			return err
+		}
+	}
+
+	referenced, err := u.uploadedFileRepo.IsReferenced(ctx, fileURL)
+	if err != nil || referenced {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(u.uploadsDir, filename)); err != nil && !os.IsNotExist(err) {
+		slog.WarnContext(ctx, "failed to remove purged file", "file", filename, "error", err)
+	}
+	if file != nil {
+		return u.uploadedFileRepo.Delete(ctx, file.ID)
+	}
+	return nil
+}
+
+// entries collects the trashed items of every content type, most recently deleted first.
+func (u *trashUsecase) entries(ctx context.Context) ([]trashEntry, error) {
+	var entries []trashEntry
+	add := func(itemType string, id int, title, imageURL string, deletedAt *time.Time, files ...string) {
+		if deletedAt == nil {
+			return
+		}
+		entries = append(entries, trashEntry{
+			item: entity.TrashItem{
+				Type:      itemType,
+				ID:        id,
+				Title:     title,
+				ImageURL:  imageURL,
+				DeletedAt: *deletedAt,
+				PurgeAt:   deletedAt.Add(u.retention),
+			},
+			files: files,
+		})
+	}
+
+	photos, err := u.kegiatanPhotoRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range photos {
+		title := p.Caption
+		if title == "" {
+			title = fmt.Sprintf("Foto kegiatan #%d", p.KegiatanID)
+		}
+		add(entity.TrashTypeKegiatanPhoto, p.ID, title, p.ImageURL, p.DeletedAt, p.ImageURL)
+	}
+
+	kegiatan, err := u.kegiatanRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range kegiatan {
+		files := append([]string{k.Cover}, markdown.ImageURLs(k.Deskripsi)...)
+		for _, f := range k.Fotos {
+			files = append(files, f.ImageURL)
+		}
+		// Photos trashed on their own go together with their kegiatan
+		for _, p := range photos {
+			if p.KegiatanID == k.ID {
+				files = append(files, p.ImageURL)
+			}
+		}
+		add(entity.TrashTypeKegiatan, k.ID, k.Judul, k.Cover, k.DeletedAt, files...)
+	}
+
+	banners, err := u.bannerRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range banners {
+		add(entity.TrashTypeBanner, b.ID, fmt.Sprintf("Banner #%d", b.ID), b.ImageURL, b.DeletedAt, b.ImageURL)
+	}
+
+	struktur, err := u.strukturRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range struktur {
+		add(entity.TrashTypeStruktur, s.ID, s.Nama+" - "+s.Jabatan, s.FotoURL, s.DeletedAt, s.FotoURL)
+	}
+
+	pembina, err := u.pembinaRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pembina {
+		add(entity.TrashTypePembina, p.ID, p.Nama+" - "+p.Jabatan, p.FotoURL, p.DeletedAt, p.FotoURL)
+	}
+
+	qrcodes, err := u.qrcodeRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range qrcodes {
+		title := q.Keterangan
+		if title == "" {
+			title = fmt.Sprintf("QR Code #%d", q.ID)
+		}
+		add(entity.TrashTypeQRCode, q.ID, title, q.ImageURL, q.DeletedAt, q.ImageURL)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].item.DeletedAt.After(entries[j].item.DeletedAt)
+	})
+	return entries, nil
+}
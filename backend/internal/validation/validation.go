@@ -0,0 +1,279 @@
+// Package validation checks request values against the rules in their `validate` struct tags
+// and reports every failing field at once as an apperror.ErrInvalidInput.
+//
+// Supported rules, separated by commas:
+//
+//	required       strings must not be blank, times must be set, pointers must not be nil
+//	required_without=F  required unless the sibling field F is set
+//	max=N          at most N characters (VARCHAR columns)
+//	maxbytes=N     at most N bytes (TEXT columns)
+//	upload         a file served from /uploads/ that was uploaded to this site
+//	nra            a member registration number such as 2021.001 or ARS-21-001
+//	gtefield=F     a time not before the sibling field F; skipped while either is unset
+//
+// Nested structs are checked too, with their fields reported as "parent.child", and so are the
+// structs in slices, reported as "parent[0].child". A tag of "-" skips a field and all it holds.
+package validation
+
+import (
+	"arshaka-backend/internal/apperror"
+	"arshaka-backend/internal/repository"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Field codes reported for failing rules. Their messages live in the apperror catalog.
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeUnknownUpload = "unknown_upload"
+	CodeNRAFormat     = "nra_format"
+	CodeBeforeStart   = "before_start"
+)
+
+// nraPattern accepts letters and digits in groups separated by single dots, dashes or slashes.
+var nraPattern = regexp.MustCompile(`^[0-9A-Za-z]+([./-][0-9A-Za-z]+)*$`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type Validator struct {
+	uploadedFileRepo repository.UploadedFileRepository
+	uploadsDir       string
+}
+
+// New returns a Validator that resolves upload URLs against uploaded_files and, for files
+// stored before uploads were recorded, against the files in uploadsDir.
+func New(uploadedFileRepo repository.UploadedFileRepository, uploadsDir string) *Validator {
+	return &Validator{
+		uploadedFileRepo: uploadedFileRepo,
+		uploadsDir:       uploadsDir,
+	}
+}
+
+// Struct validates v, a struct or a pointer to one. It returns nil when every rule holds,
+// ErrInvalidInput naming the failing fields otherwise, or the error of an upload lookup.
+func (v *Validator) Struct(ctx context.Context, s interface{}) error {
+	return v.validate(ctx, s, nil)
+}
+
+// Update validates s as the new state of stored. Upload URLs stored already pass as they are,
+// so rows that point at seeded or external images stay editable.
+func (v *Validator) Update(ctx context.Context, s, stored interface{}) error {
+	kept := map[string]bool{}
+	collectUploads(reflect.Indirect(reflect.ValueOf(stored)), kept)
+	return v.validate(ctx, s, kept)
+}
+
+func (v *Validator) validate(ctx context.Context, s interface{}, kept map[string]bool) error {
+	value := reflect.Indirect(reflect.ValueOf(s))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string]string{}
+	if err := v.walk(ctx, value, "", kept, fields); err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return apperror.ErrInvalidInput.WithFields(fields)
+	}
+	return nil
+}
+
+func (v *Validator) walk(ctx context.Context, value reflect.Value, prefix string, kept map[string]bool, fields map[string]string) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name := prefix + fieldName(sf)
+		field := value.Field(i)
+
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			if err := v.walk(ctx, field, name+".", kept, fields); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				code, err := v.check(ctx, value, field, rule, kept)
+				if err != nil {
+					return err
+				}
+				if code != "" {
+					// The first failing rule is the one worth fixing first
+					fields[name] = code
+					break
+				}
+			}
+		}
+
+		if field.Kind() == reflect.Slice {
+			for j := 0; j < field.Len(); j++ {
+				elem := reflect.Indirect(field.Index(j))
+				if elem.Kind() != reflect.Struct || elem.Type() == timeType {
+					continue
+				}
+				if err := v.walk(ctx, elem, fmt.Sprintf("%s[%d].", name, j), kept, fields); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// check applies a single rule to field and returns the code of the failure, if any.
+func (v *Validator) check(ctx context.Context, parent, field reflect.Value, rule string, kept map[string]bool) (string, error) {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+
+	switch name {
+	case "required":
+		if isBlank(field) {
+			return CodeRequired, nil
+		}
+	case "required_without":
+		if other := parent.FieldByName(param); isBlank(field) && (!other.IsValid() || isBlank(other)) {
+			return CodeRequired, nil
+		}
+	case "max":
+		limit, _ := strconv.Atoi(param)
+		if field.Kind() == reflect.String && utf8.RuneCountInString(field.String()) > limit {
+			return CodeTooLong, nil
+		}
+	case "maxbytes":
+		limit, _ := strconv.Atoi(param)
+		if field.Kind() == reflect.String && len(field.String()) > limit {
+			return CodeTooLong, nil
+		}
+	case "upload":
+		if field.Kind() != reflect.String || field.String() == "" || kept[field.String()] {
+			return "", nil
+		}
+		known, err := v.isKnownUpload(ctx, field.String())
+		if err != nil {
+			return "", err
+		}
+		if !known {
+			return CodeUnknownUpload, nil
+		}
+	case "nra":
+		if field.Kind() == reflect.String && field.String() != "" && !nraPattern.MatchString(field.String()) {
+			return CodeNRAFormat, nil
+		}
+	case "gtefield":
+		start, ok := timeValue(parent.FieldByName(param))
+		end, ok2 := timeValue(field)
+		if ok && ok2 && !start.IsZero() && !end.IsZero() && end.Before(start) {
+			return CodeBeforeStart, nil
+		}
+	}
+	return "", nil
+}
+
+// isKnownUpload reports whether url points at a file uploaded through this site.
+func (v *Validator) isKnownUpload(ctx context.Context, url string) (bool, error) {
+	filename := strings.TrimPrefix(url, "/uploads/")
+	if filename == url || filename == "" || strings.ContainsAny(filename, `/\`) {
+		return false, nil
+	}
+
+	file, err := v.uploadedFileRepo.GetByFilename(ctx, filename)
+	if err != nil {
+		return false, err
+	}
+	if file != nil {
+		return true, nil
+	}
+
+	info, err := os.Stat(filepath.Join(v.uploadsDir, filename))
+	return err == nil && info.Mode().IsRegular(), nil
+}
+
+// collectUploads adds the value of every upload field in value, nested ones included, to urls.
+func collectUploads(value reflect.Value, urls map[string]bool) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			collectUploads(value.Elem(), urls)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			collectUploads(value.Index(i), urls)
+		}
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			field := value.Field(i)
+			if field.Kind() == reflect.String && hasRule(sf.Tag.Get("validate"), "upload") {
+				urls[field.String()] = true
+				continue
+			}
+			collectUploads(field, urls)
+		}
+	}
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(field.String()) == ""
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return field.IsNil()
+	}
+	if t, ok := timeValue(field); ok {
+		return t.IsZero()
+	}
+	return field.IsZero()
+}
+
+func timeValue(field reflect.Value) (time.Time, bool) {
+	if !field.IsValid() {
+		return time.Time{}, false
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return time.Time{}, true
+		}
+		field = field.Elem()
+	}
+	t, ok := field.Interface().(time.Time)
+	return t, ok
+}
+
+// fieldName is the JSON name clients use for the field.
+func fieldName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,9 @@
+-- Migration: Add publishing workflow to kegiatan
+-- New kegiatan start as draft; existing rows were already public so they are marked published.
+
+ALTER TABLE kegiatan
+    ADD COLUMN status ENUM('draft', 'published', 'archived') NOT NULL DEFAULT 'draft' AFTER tanggal,
+    ADD COLUMN publish_at DATETIME NULL AFTER status,
+    ADD INDEX idx_status_publish_at (status, publish_at);
+
+UPDATE kegiatan SET status = 'published';
@@ -0,0 +1,17 @@
+-- Migration: Add SEO-friendly slugs to kegiatan
+-- Existing rows are given a slug by the backend on startup.
+
+ALTER TABLE kegiatan
+    ADD COLUMN slug VARCHAR(191) NULL AFTER judul,
+    ADD UNIQUE INDEX idx_kegiatan_slug (slug);
+
+-- Previous slugs are kept so old shared links keep redirecting
+CREATE TABLE IF NOT EXISTS kegiatan_slug_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    kegiatan_id INT NOT NULL,
+    slug VARCHAR(191) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (kegiatan_id) REFERENCES kegiatan(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_slug (slug),
+    INDEX idx_kegiatan_id (kegiatan_id)
+);
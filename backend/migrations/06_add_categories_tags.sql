@@ -0,0 +1,44 @@
+-- Migration: Add categories and tags for kegiatan
+
+-- Admin managed categories (latihan rutin, perkemahan, bakti sosial, ...)
+CREATE TABLE IF NOT EXISTS categories (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_slug (slug)
+);
+
+-- Free-form tags, created on the fly when a kegiatan is saved
+CREATE TABLE IF NOT EXISTS tags (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_slug (slug)
+);
+
+CREATE TABLE IF NOT EXISTS kegiatan_categories (
+    kegiatan_id INT NOT NULL,
+    category_id INT NOT NULL,
+    PRIMARY KEY (kegiatan_id, category_id),
+    FOREIGN KEY (kegiatan_id) REFERENCES kegiatan(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    INDEX idx_category_id (category_id)
+);
+
+CREATE TABLE IF NOT EXISTS kegiatan_tags (
+    kegiatan_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (kegiatan_id, tag_id),
+    FOREIGN KEY (kegiatan_id) REFERENCES kegiatan(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_tag_id (tag_id)
+);
+
+INSERT INTO categories (name, slug, description) VALUES
+('Latihan Rutin', 'latihan-rutin', 'Latihan mingguan anggota'),
+('Perkemahan', 'perkemahan', 'Kegiatan berkemah dan penjelajahan'),
+('Bakti Sosial', 'bakti-sosial', 'Kegiatan pengabdian kepada masyarakat');
@@ -0,0 +1,22 @@
+-- Migration: Multi-day kegiatan with time ranges and location
+-- start_at and end_at hold the wall-clock time in the kegiatan's own timezone.
+
+ALTER TABLE kegiatan
+    ADD COLUMN start_at DATETIME NULL AFTER tanggal,
+    ADD COLUMN end_at DATETIME NULL AFTER start_at,
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta' AFTER end_at,
+    ADD COLUMN location_name VARCHAR(255) NULL AFTER timezone,
+    ADD COLUMN location_address VARCHAR(500) NULL AFTER location_name,
+    ADD COLUMN latitude DECIMAL(10, 7) NULL AFTER location_address,
+    ADD COLUMN longitude DECIMAL(10, 7) NULL AFTER latitude,
+    ADD INDEX idx_start_end (start_at, end_at);
+
+-- Existing kegiatan become single full-day events on their tanggal
+UPDATE kegiatan
+SET start_at = TIMESTAMP(tanggal),
+    end_at = TIMESTAMP(tanggal, '23:59:59')
+WHERE start_at IS NULL;
+
+ALTER TABLE kegiatan
+    MODIFY start_at DATETIME NOT NULL,
+    MODIFY end_at DATETIME NOT NULL;
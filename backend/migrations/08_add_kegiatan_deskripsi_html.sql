@@ -0,0 +1,7 @@
+-- Migration: Rich-text kegiatan descriptions
+-- deskripsi holds the Markdown source, deskripsi_html the sanitized rendering served to clients.
+-- Existing rows are rendered on read until they are next saved.
+
+ALTER TABLE kegiatan
+    MODIFY COLUMN deskripsi MEDIUMTEXT,
+    ADD COLUMN deskripsi_html MEDIUMTEXT NULL AFTER deskripsi;
@@ -0,0 +1,10 @@
+-- Migration: Soft delete for content types
+-- Deleting moves a row to the trash by setting deleted_at. Rows are purged for good by the
+-- retention job after TRASH_RETENTION_DAYS days.
+
+ALTER TABLE kegiatan ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
+ALTER TABLE kegiatan_photos ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
+ALTER TABLE banners ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
+ALTER TABLE struktur ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
+ALTER TABLE pembina ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
+ALTER TABLE qr_code ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_deleted_at (deleted_at);
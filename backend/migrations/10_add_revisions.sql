@@ -0,0 +1,13 @@
+-- Migration: Revision history
+-- Every save of a kegiatan or struktur stores a JSON snapshot so edits can be compared and rolled back.
+
+CREATE TABLE IF NOT EXISTS revisions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL, -- kegiatan, struktur
+    entity_id INT NOT NULL,
+    version INT NOT NULL,
+    snapshot JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY unique_version (entity_type, entity_id, version)
+);
@@ -0,0 +1,12 @@
+-- Migration: Row versions for optimistic concurrency
+-- Every update bumps version. Admin GETs return it as an ETag and updates sent with If-Match
+-- (or a version in the body) are rejected with 412 when someone else saved in between.
+
+ALTER TABLE kegiatan ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE kegiatan_photos ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE banners ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE struktur ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE pembina ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE qr_code ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE tags ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,26 @@
+-- Migration: Schema migration tracking
+-- Records which migration files have been applied so /readyz can report pending ones. Every later
+-- migration ends by inserting its own file name here.
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    filename VARCHAR(255) PRIMARY KEY,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Databases reaching this point already have every earlier migration
+INSERT IGNORE INTO schema_migrations (filename) VALUES
+    ('01_init.sql'),
+    ('02_add_kegiatan_photos.sql'),
+    ('02_add_pembina_table.sql'),
+    ('02_add_qrcode_keterangan.sql'),
+    ('02_uploaded_files.sql'),
+    ('03_add_nra_to_struktur.sql'),
+    ('04_add_kegiatan_status.sql'),
+    ('05_add_kegiatan_slug.sql'),
+    ('06_add_categories_tags.sql'),
+    ('07_add_kegiatan_schedule_location.sql'),
+    ('08_add_kegiatan_deskripsi_html.sql'),
+    ('09_add_soft_delete.sql'),
+    ('10_add_revisions.sql'),
+    ('11_add_row_versions.sql'),
+    ('12_add_schema_migrations.sql');
@@ -0,0 +1,52 @@
+// Package migrations embeds the SQL migration files so the server knows which schema it expects.
+// MySQL applies them from docker-entrypoint-initdb.d in file name order.
+package migrations
+
+import (
+	"embed"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Names returns the migration file names in the order they are applied.
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SchemaVersion is the number prefix of the newest migration, e.g. 12 for 12_add_schema_migrations.sql.
+func SchemaVersion() int {
+	version := 0
+	for _, name := range Names() {
+		prefix, _, _ := strings.Cut(name, "_")
+		if n, err := strconv.Atoi(prefix); err == nil && n > version {
+			version = n
+		}
+	}
+	return version
+}
+
+// Pending returns the embedded migrations missing from applied.
+func Pending(applied []string) []string {
+	done := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		done[name] = true
+	}
+
+	var pending []string
+	for _, name := range Names() {
+		if !done[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,156 @@
+// Package cache is an in-memory TTL cache whose entries belong to groups that can be invalidated
+// together, e.g. every cached response about kegiatan once one of them changes.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats are counted per group since the cache was created.
+type Stats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+type entry struct {
+	group   string
+	value   interface{}
+	expires time.Time
+}
+
+type Cache struct {
+	maxEntries int
+	created    time.Time
+
+	mu          sync.Mutex
+	entries     map[string]entry
+	generations map[string]uint64
+	changed     map[string]time.Time
+	stats       map[string]*Stats
+}
+
+// New returns a cache holding at most maxEntries values, so a flood of distinct keys such as
+// random query strings cannot grow it without bound.
+func New(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries:  maxEntries,
+		created:     time.Now(),
+		entries:     make(map[string]entry),
+		generations: make(map[string]uint64),
+		changed:     make(map[string]time.Time),
+		stats:       make(map[string]*Stats),
+	}
+}
+
+// Get returns the unexpired value stored under key and counts a hit or miss for group.
+func (c *Cache) Get(group, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if ok && time.Now().After(e.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if ok {
+		c.statsOf(group).Hits++
+		return e.value, true
+	}
+	c.statsOf(group).Misses++
+	return nil, false
+}
+
+// Generation identifies the state of group. Read it before computing a value and pass it to Set,
+// so a value computed from data that changed meanwhile is not stored.
+func (c *Cache) Generation(group string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[group]
+}
+
+// Changed is when group was last invalidated, or when the cache was created if it never was.
+// Nothing in the group changed since, whether rows were edited, deleted or unpublished.
+func (c *Cache) Changed(group string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if changed, ok := c.changed[group]; ok {
+		return changed
+	}
+	return c.created
+}
+
+// Set stores value under key for ttl unless group was invalidated since generation was read.
+func (c *Cache) Set(group, key string, generation uint64, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[group] != generation {
+		return
+	}
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.removeExpired()
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = entry{group: group, value: value, expires: time.Now().Add(ttl)}
+}
+
+// Invalidate drops every entry of the given groups and records that they changed.
+func (c *Cache) Invalidate(groups ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, group := range groups {
+		c.generations[group]++
+		c.changed[group] = now
+		c.statsOf(group).Invalidations++
+		for key, e := range c.entries {
+			if e.group == group {
+				delete(c.entries, key)
+			}
+		}
+	}
+}
+
+// Stats returns the counters of every group seen so far.
+func (c *Cache) Stats() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]Stats, len(c.stats))
+	for group, s := range c.stats {
+		result[group] = *s
+	}
+	now := time.Now()
+	for _, e := range c.entries {
+		if now.Before(e.expires) {
+			s := result[e.group]
+			s.Entries++
+			result[e.group] = s
+		}
+	}
+	return result
+}
+
+func (c *Cache) statsOf(group string) *Stats {
+	s, ok := c.stats[group]
+	if !ok {
+		s = &Stats{}
+		c.stats[group] = s
+	}
+	return s
+}
+
+func (c *Cache) removeExpired() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
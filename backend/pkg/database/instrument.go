@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedConnector hands out connections that trace every statement and log the failed ones
+// with the context of the call, so spans join the request's trace and log lines carry its
+// request ID.
+type instrumentedConnector struct {
+	driver.Connector
+}
+
+func (c instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{conn: conn}, nil
+}
+
+// The MySQL driver implements all of these, so the assertions in instrumentedConn always succeed.
+type mysqlConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	driver.NamedValueChecker
+}
+
+type instrumentedConn struct {
+	conn driver.Conn
+}
+
+func (c *instrumentedConn) inner() mysqlConn {
+	return c.conn.(mysqlConn)
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.inner().PrepareContext(ctx, query)
+	if err != nil {
+		// A statement that cannot be prepared never runs, so its span only records the failure
+		_, end := startQuery(ctx, query)
+		end(err)
+		return nil, err
+	}
+	return &instrumentedStmt{stmt: stmt, query: query}, nil
+}
+
+func (c *instrumentedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *instrumentedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	ctx, end := startQuery(ctx, "BEGIN")
+	tx, err := c.inner().BeginTx(ctx, opts)
+	end(err)
+	return tx, err
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	// Without interpolateParams in the DSN the driver skips statements with arguments here; they
+	// come back through a prepared statement, which is traced instead
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := startQuery(ctx, query)
+	result, err := c.inner().ExecContext(ctx, query, args)
+	end(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := startQuery(ctx, query)
+	rows, err := c.inner().QueryContext(ctx, query, args)
+	end(err)
+	return rows, err
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	return c.inner().Ping(ctx)
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	return c.inner().ResetSession(ctx)
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	return c.inner().IsValid()
+}
+
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return c.inner().CheckNamedValue(nv)
+}
+
+type mysqlStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+type instrumentedStmt struct {
+	stmt  driver.Stmt
+	query string
+}
+
+func (s *instrumentedStmt) inner() mysqlStmt {
+	return s.stmt.(mysqlStmt)
+}
+
+func (s *instrumentedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *instrumentedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec and Query are only part of driver.Stmt; database/sql uses the context variants below.
+func (s *instrumentedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.stmt.Exec(args)
+}
+
+func (s *instrumentedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.stmt.Query(args)
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, end := startQuery(ctx, s.query)
+	result, err := s.inner().ExecContext(ctx, args)
+	end(err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, end := startQuery(ctx, s.query)
+	rows, err := s.inner().QueryContext(ctx, args)
+	end(err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+var tracer = otel.Tracer("arshaka-backend/pkg/database")
+
+// startQuery starts a span for query. The returned function ends it, recording and logging err.
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	query = strings.Join(strings.Fields(query), " ")
+	ctx, span := tracer.Start(ctx, spanName(query), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemMySQL, semconv.DBStatement(query)))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logQueryError(ctx, query, err)
+		}
+		span.End()
+	}
+}
+
+// spanName is the SQL verb and the table it works on, e.g. "SELECT kegiatan_foto".
+func spanName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "query"
+	}
+	verb := strings.ToUpper(fields[0])
+	tableAfter := map[string]string{"SELECT": "FROM", "DELETE": "FROM", "INSERT": "INTO", "UPDATE": "UPDATE"}[verb]
+	for i := 0; tableAfter != "" && i+1 < len(fields); i++ {
+		if strings.EqualFold(fields[i], tableAfter) {
+			return verb + " " + strings.Trim(fields[i+1], "`(")
+		}
+	}
+	return verb
+}
+
+// logQueryError logs a failed statement. Duplicate keys and cancelled requests are expected now
+// and then, so they are warnings.
+func logQueryError(ctx context.Context, query string, err error) {
+	level := slog.LevelError
+	var mysqlErr *mysql.MySQLError
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &mysqlErr) && mysqlErr.Number == 1062) {
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "query failed", "query", strings.Join(strings.Fields(query), " "), "error", err)
+}
@@ -3,10 +3,10 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 type Config struct {
@@ -26,16 +26,21 @@ func NewMySQLConnection(config Config) (*sql.DB, error) {
 		config.DBName,
 	)
 
-	db, err := sql.Open("mysql", dsn)
+	dsnConfig, err := mysql.ParseDSN(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	connector, err := mysql.NewConnector(dsnConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db := sql.OpenDB(instrumentedConnector{connector})
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Successfully connected to MySQL database")
+	slog.Info("connected to MySQL", "host", config.Host, "database", config.DBName)
 	return db, nil
 }
 
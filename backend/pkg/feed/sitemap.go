@@ -0,0 +1,43 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	sitemapNamespace      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	imageSitemapNamespace = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+type Sitemap struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsImage string       `xml:"xmlns:image,attr"`
+	URLs       []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc        string         `xml:"loc"`
+	LastMod    string         `xml:"lastmod,omitempty"`
+	ChangeFreq string         `xml:"changefreq,omitempty"`
+	Priority   string         `xml:"priority,omitempty"`
+	Images     []SitemapImage `xml:"image:image"`
+}
+
+type SitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+// FormatDate formats t in the W3C datetime format used by sitemaps.
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Encode writes the sitemap as an XML document.
+func (s *Sitemap) Encode(w io.Writer) error {
+	s.Xmlns = sitemapNamespace
+	s.XmlnsImage = imageSitemapNamespace
+	return encode(w, s)
+}
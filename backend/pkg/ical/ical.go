@@ -0,0 +1,135 @@
+// Package ical writes RFC 5545 iCalendar documents.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const utcLayout = "20060102T150405Z"
+
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Timezone string
+	Events   []Event
+}
+
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Latitude     *float64
+	Longitude    *float64
+	URL          string
+	Categories   []string
+	Start        time.Time
+	End          time.Time
+	Created      time.Time
+	LastModified time.Time
+	Sequence     int
+}
+
+// Encode writes the calendar to w using CRLF line endings and 75 octet line folding.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", escapeText(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.Timezone != "" {
+		line("X-WR-TIMEZONE", c.Timezone)
+	}
+
+	for _, e := range c.Events {
+		stamp := e.LastModified
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", formatUTC(stamp))
+		line("DTSTART", formatUTC(e.Start))
+		line("DTEND", formatUTC(e.End))
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.Latitude != nil && e.Longitude != nil {
+			line("GEO", fmt.Sprintf("%f;%f", *e.Latitude, *e.Longitude))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				escaped[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if !e.Created.IsZero() {
+			line("CREATED", formatUTC(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED", formatUTC(e.LastModified))
+		}
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("STATUS", "CONFIRMED")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
+
+// escapeText escapes a TEXT value as described in RFC 5545 section 3.3.11.
+func escapeText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// writeFolded writes a content line, folding it so that no line exceeds 75 octets
+// without splitting a multi-byte UTF-8 character.
+func writeFolded(w *bufio.Writer, content string) {
+	const limit = 75
+
+	lineLen := 0
+	for _, r := range content {
+		size := len(string(r))
+		if lineLen+size > limit {
+			w.WriteString("\r\n ")
+			lineLen = 1
+		}
+		w.WriteRune(r)
+		lineLen += size
+	}
+	w.WriteString("\r\n")
+}
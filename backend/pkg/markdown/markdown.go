@@ -0,0 +1,376 @@
+// Package markdown renders the restricted Markdown dialect used for kegiatan descriptions.
+//
+// Raw HTML is never passed through: every character of the source is escaped and only the
+// tags produced by the renderer itself can appear in the output, so the result is safe to
+// embed in a page. Links and images are limited to http, https, mailto and site-relative URLs.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options controls optional features of the renderer.
+type Options struct {
+	// AllowImage decides whether an image may be embedded. Images are replaced by their
+	// alt text when AllowImage is nil or returns false.
+	AllowImage func(src string) bool
+}
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rulePattern        = regexp.MustCompile(`^\s{0,3}(-(\s*-){2,}|\*(\s*\*){2,}|_(\s*_){2,})\s*$`)
+	unorderedPattern   = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^\s{0,3}(\d{1,9})[.)]\s+(.*)$`)
+	quotePattern       = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	fencePattern       = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	tagPattern         = regexp.MustCompile(`<[^>]*>`)
+	blockBreakPattern  = regexp.MustCompile(`<br>|</(p|h[1-6]|li|blockquote|pre)>`)
+	blankLinesPattern  = regexp.MustCompile(`\n{2,}`)
+	inlineSpacePattern = regexp.MustCompile(`[ \t]+`)
+)
+
+// ToHTML renders src to sanitized HTML.
+func ToHTML(src string, opts Options) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), opts)
+	return strings.TrimSpace(b.String())
+}
+
+// ImageURLs returns the source of every image referenced in src.
+func ImageURLs(src string) []string {
+	var urls []string
+	ToHTML(src, Options{AllowImage: func(u string) bool {
+		urls = append(urls, u)
+		return false
+	}})
+	return urls
+}
+
+// PlainText strips all formatting from src, keeping one line per block or line break.
+func PlainText(src string) string {
+	text := blockBreakPattern.ReplaceAllString(ToHTML(src, Options{}), "\n")
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, ""))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(inlineSpacePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n"))
+}
+
+func renderBlocks(b *strings.Builder, lines []string, opts Options) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			fence := fencePattern.FindStringSubmatch(line)[1]
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // closing fence
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			// The page title is the h1, so description headings start at h2
+			level := len(m[1]) + 1
+			if level > 6 {
+				level = 6
+			}
+			tag := "h" + strconv.Itoa(level)
+			b.WriteString("<" + tag + ">")
+			renderInline(b, m[2], opts)
+			b.WriteString("</" + tag + ">\n")
+			i++
+
+		case rulePattern.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case quotePattern.MatchString(line):
+			var quoted []string
+			for i < len(lines) && quotePattern.MatchString(lines[i]) {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[1])
+				i++
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted, opts)
+			b.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(line) || orderedPattern.MatchString(line):
+			i = renderList(b, lines, i, opts)
+
+		default:
+			var para []string
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && (len(para) == 0 || !startsBlock(lines[i])) {
+				para = append(para, strings.TrimSpace(lines[i]))
+				i++
+			}
+			b.WriteString("<p>")
+			for j, p := range para {
+				if j > 0 {
+					b.WriteString("<br>\n")
+				}
+				renderInline(b, p, opts)
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+func startsBlock(line string) bool {
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) || rulePattern.MatchString(line) ||
+		quotePattern.MatchString(line) || unorderedPattern.MatchString(line) || orderedPattern.MatchString(line)
+}
+
+// renderList renders a flat list starting at lines[start] and returns the index after it.
+func renderList(b *strings.Builder, lines []string, start int, opts Options) int {
+	ordered := orderedPattern.MatchString(lines[start]) && !unorderedPattern.MatchString(lines[start])
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if n := orderedPattern.FindStringSubmatch(lines[start])[1]; n != "1" {
+			num, _ := strconv.Atoi(n)
+			b.WriteString(`<ol start="` + strconv.Itoa(num) + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	var items []string
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if ordered && orderedPattern.MatchString(line) {
+			items = append(items, orderedPattern.FindStringSubmatch(line)[2])
+		} else if !ordered && unorderedPattern.MatchString(line) {
+			items = append(items, unorderedPattern.FindStringSubmatch(line)[1])
+		} else if strings.TrimSpace(line) != "" && !startsBlock(line) && len(items) > 0 {
+			// Lazy continuation of the previous item
+			items[len(items)-1] += " " + strings.TrimSpace(line)
+		} else {
+			break
+		}
+		i++
+	}
+
+	for _, item := range items {
+		b.WriteString("<li>")
+		renderInline(b, item, opts)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+func renderInline(b *strings.Builder, text string, opts Options) {
+	// Where each bracket closes, worked out on the first one seen
+	var closing map[int]int
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!>~|", text[i+1]) >= 0:
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(text[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>")
+				b.WriteString(html.EscapeString(text[i+1 : i+1+end]))
+				b.WriteString("</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			if closing == nil {
+				closing = matchBrackets(text)
+			}
+			if alt, dest, n, ok := parseLink(text, i+1, closing); ok {
+				if opts.AllowImage != nil && isSafeURL(dest) && opts.AllowImage(dest) {
+					b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(alt) + `" loading="lazy">`)
+				} else {
+					b.WriteString(html.EscapeString(alt))
+				}
+				i += n + 1
+				continue
+			}
+
+		case c == '[':
+			if closing == nil {
+				closing = matchBrackets(text)
+			}
+			if label, dest, n, ok := parseLink(text, i, closing); ok {
+				if isSafeURL(dest) {
+					b.WriteString(`<a href="` + html.EscapeString(dest) + `" rel="nofollow noopener noreferrer">`)
+					renderInline(b, label, Options{})
+					b.WriteString("</a>")
+				} else {
+					renderInline(b, label, Options{})
+				}
+				i += n
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if n := emphasis(b, text, i, opts); n > 0 {
+				i += n
+				continue
+			}
+		}
+
+		// Copy the next UTF-8 character as escaped text
+		j := i + 1
+		for j < len(text) && text[j]&0xC0 == 0x80 {
+			j++
+		}
+		b.WriteString(html.EscapeString(text[i:j]))
+		i = j
+	}
+}
+
+// emphasis renders **strong** or *em* starting at text[i] and returns the number of bytes consumed.
+func emphasis(b *strings.Builder, text string, i int, opts Options) int {
+	c := text[i]
+
+	// Intraword underscores (snake_case) are literal
+	if c == '_' && i > 0 && isWordByte(text[i-1]) {
+		return 0
+	}
+
+	delim := string(c)
+	tag := "em"
+	if strings.HasPrefix(text[i:], delim+delim) {
+		delim += delim
+		tag = "strong"
+	}
+
+	start := i + len(delim)
+	if start >= len(text) || text[start] == ' ' {
+		return 0
+	}
+
+	// A single delimiter does not close on a double one, so *a **b** c* nests
+	end := -1
+	for j := start; j < len(text) && end < 0; j++ {
+		switch {
+		case !strings.HasPrefix(text[j:], delim):
+		case len(delim) == 1 && j+1 < len(text) && text[j+1] == c:
+			j++
+		default:
+			end = j - start
+		}
+	}
+	if end <= 0 || text[start+end-1] == ' ' {
+		return 0
+	}
+	after := start + end + len(delim)
+	if c == '_' && after < len(text) && isWordByte(text[after]) {
+		return 0
+	}
+
+	b.WriteString("<" + tag + ">")
+	renderInline(b, text[start:start+end], opts)
+	b.WriteString("</" + tag + ">")
+	return after - i
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// matchBrackets pairs every [ and ( in text with the ] or ) that closes it, skipping escaped
+// characters. Doing this in one pass keeps links linear however many brackets stay open.
+func matchBrackets(text string) map[int]int {
+	closing := make(map[int]int)
+	var squares, parens []int
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			squares = append(squares, i)
+		case '(':
+			parens = append(parens, i)
+		case ']':
+			if len(squares) > 0 {
+				closing[squares[len(squares)-1]] = i
+				squares = squares[:len(squares)-1]
+			}
+		case ')':
+			if len(parens) > 0 {
+				closing[parens[len(parens)-1]] = i
+				parens = parens[:len(parens)-1]
+			}
+		}
+	}
+	return closing
+}
+
+// parseLink parses "[label](destination)" at text[start], given the bracket pairs of text.
+// n is the length of the link in bytes.
+func parseLink(text string, start int, closing map[int]int) (label, dest string, n int, ok bool) {
+	closeLabel, found := closing[start]
+	if !found || closeLabel+1 >= len(text) || text[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+	// Destinations may contain balanced parentheses
+	closeDest, found := closing[closeLabel+1]
+	if !found {
+		return "", "", 0, false
+	}
+
+	dest = strings.TrimSpace(text[closeLabel+2 : closeDest])
+	// Drop an optional title: [x](url "title")
+	if sp := strings.IndexAny(dest, " \t"); sp >= 0 {
+		dest = dest[:sp]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+
+	return text[start+1 : closeLabel], dest, closeDest + 1 - start, true
+}
+
+// isSafeURL allows http, https and mailto URLs as well as paths on this site.
+func isSafeURL(raw string) bool {
+	if raw == "" || strings.ContainsAny(raw, "\x00\t\n\r\"'<>` ") {
+		return false
+	}
+	if strings.HasPrefix(raw, "/") {
+		return !strings.HasPrefix(raw, "//") && !strings.HasPrefix(raw, "/\\")
+	}
+	if strings.HasPrefix(raw, "#") {
+		return true
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func allowAll(string) bool { return true }
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		// Unsafe input
+		{"raw html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"javascript link", "[click](javascript:alert(1))", "<p>click</p>"},
+		{"mixed case javascript link", "[click](JaVaScRiPt:alert(1))", "<p>click</p>"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>"},
+		{"data image", "![x](data:image/svg+xml,<svg onload=alert(1)>)", "<p>x</p>"},
+		{"javascript image", "![x](javascript:alert(1))", "<p>x</p>"},
+		{"protocol relative link", "[x](//evil.example)", "<p>x</p>"},
+		{"quote in destination", `[a"b](https://example.com/?q="><script>)`, "<p>a&#34;b</p>"},
+		{"quote in alt", `![a" onerror="alert(1)](/uploads/a.jpg)`,
+			`<p><img src="/uploads/a.jpg" alt="a&#34; onerror=&#34;alert(1)" loading="lazy"></p>`},
+		{"ampersand in href", "[x](https://example.com/a&b)",
+			`<p><a href="https://example.com/a&amp;b" rel="nofollow noopener noreferrer">x</a></p>`},
+
+		// Links and images
+		{"mailto link", "[x](mailto:a@b.c)", `<p><a href="mailto:a@b.c" rel="nofollow noopener noreferrer">x</a></p>`},
+		{"parentheses in destination", "[link](https://example.com/x_(y))",
+			`<p><a href="https://example.com/x_(y)" rel="nofollow noopener noreferrer">link</a></p>`},
+		{"image title dropped", `![alt](/uploads/a.jpg "title")`, `<p><img src="/uploads/a.jpg" alt="alt" loading="lazy"></p>`},
+		{"unclosed label", "[unclosed link", "<p>[unclosed link</p>"},
+		{"unclosed destination", "[label](https://example.com", "<p>[label](https://example.com</p>"},
+		{"link after unclosed bracket", "[a [b](https://b.example) c",
+			`<p>[a <a href="https://b.example" rel="nofollow noopener noreferrer">b</a> c</p>`},
+
+		// Emphasis
+		{"em in strong", "**bold *and italic* text**", "<p><strong>bold <em>and italic</em> text</strong></p>"},
+		{"strong in em", "*em **strong** em*", "<p><em>em <strong>strong</strong> em</em></p>"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>"},
+		{"unclosed emphasis", "*not emphasis", "<p>*not emphasis</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.src, Options{AllowImage: allowAll}); got != tt.want {
+				t.Errorf("ToHTML(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToHTMLImagesNeedPermission(t *testing.T) {
+	got := ToHTML("![alt](/uploads/a.jpg)", Options{})
+	if want := "<p>alt</p>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestToHTMLOpenBracketsAreLinear(t *testing.T) {
+	for _, src := range []string{strings.Repeat("[", 200000), strings.Repeat("[a](", 200000)} {
+		start := time.Now()
+		ToHTML(src, Options{})
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("rendering %d bytes of open brackets took %s", len(src), elapsed)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package mergepatch implements JSON Merge Patch as described in RFC 7396.
+package mergepatch
+
+// Apply merges patch into target and returns the result. Both are JSON values decoded into
+// interface{} (objects as map[string]interface{}). An object patch is merged key by key, a null
+// member removes the key, and any other patch value replaces the target entirely.
+// target is modified in place when it is an object.
+func Apply(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = Apply(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,51 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// The cases are the examples of RFC 7396, appendix A.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null deletes only that member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested object merged", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"arrays are not merged", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"array target replaced", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"object target replaced by array", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null patch replaces target", `{"a":"foo"}`, `null`, `null`},
+		{"string patch replaces target", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"null inside new member kept", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"array target becomes object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"nested object created", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Apply(decode(t, tt.target), decode(t, tt.patch))
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply(%s, %s) = %v, want %s", tt.target, tt.patch, got, tt.want)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decoding %s: %v", s, err)
+	}
+	return v
+}
@@ -0,0 +1,107 @@
+// Package ratelimit implements token buckets: a bucket holds up to Burst tokens, refills at
+// PerMinute tokens a minute and every request takes one. Buckets live in a Store, in memory for a
+// single instance or in Redis when several instances must share them.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Result describes a bucket after a request tried to take a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next token, zero when the request was allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill returns the tokens a bucket holds after elapsed, given it held tokens before.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	tokens += elapsed.Minutes() * float64(limit.PerMinute)
+	return math.Min(tokens, float64(limit.Burst))
+}
+
+// result describes a bucket left holding tokens after a request was allowed or not.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	perToken := float64(time.Minute) / float64(limit.PerMinute)
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(limit.Burst) - tokens) * perToken),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return r
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled, after which it may be forgotten
+	full time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweep   time.Time
+}
+
+// NewMemoryStore keeps buckets in this process. Full buckets are dropped now and then, so
+// addresses seen once do not pile up.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), sweep: time.Now()}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.sweep) > time.Minute {
+		s.removeFull(now)
+		s.sweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.last), limit)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	r := result(allowed, b.tokens, limit)
+	b.full = now.Add(r.Reset)
+	return r, nil
+}
+
+// removeFull drops buckets that have refilled; a new bucket starts out just as full.
+func (s *MemoryStore) removeFull(now time.Time) {
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from a bucket stored as a hash of tokens and the time of the last
+// request in milliseconds, atomically so concurrent instances cannot both take the last token.
+// The clock of the Redis server is used, so the instances' clocks need not agree.
+var takeScript = redis.NewScript(`
+local per_minute = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + (now - last) / 60000 * per_minute)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / per_minute * 60000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore keeps buckets in Redis under keys starting with prefix. Each bucket expires once
+// it has refilled.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// NewRedisClient connects to the server named by a redis:// or rediss:// URL and checks that it
+// answers.
+func NewRedisClient(ctx context.Context, url string) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	// Lua numbers become integers on the way out, so the fractional tokens come back as a string
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.PerMinute, limit.Burst).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script reply %v", reply)
+	}
+	allowed, _ := reply[0].(int64)
+	text, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: unexpected token count %q", text)
+	}
+	return result(allowed == 1, tokens, limit), nil
+}
@@ -0,0 +1,65 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength caps generated slugs so they fit comfortably in an indexed VARCHAR column.
+const MaxLength = 80
+
+// transliterations maps common non-ASCII Latin letters to their ASCII spelling.
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	'&': " dan ",
+}
+
+// Make turns a title into a lowercase, ASCII, hyphen separated slug.
+// It returns an empty string when the title has no usable characters.
+func Make(title string) string {
+	var b strings.Builder
+	lastHyphen := true
+
+	for _, r := range strings.ToLower(title) {
+		if t, ok := transliterations[r]; ok {
+			for _, tr := range t {
+				lastHyphen = writeRune(&b, tr, lastHyphen)
+			}
+			continue
+		}
+		lastHyphen = writeRune(&b, r, lastHyphen)
+	}
+
+	return truncate(strings.TrimSuffix(b.String(), "-"), MaxLength)
+}
+
+func writeRune(b *strings.Builder, r rune, lastHyphen bool) bool {
+	if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		b.WriteRune(r)
+		return false
+	}
+	if !lastHyphen {
+		b.WriteByte('-')
+	}
+	return true
+}
+
+// truncate shortens s to at most max bytes, cutting at a hyphen where possible.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, "-")
+}
@@ -0,0 +1,83 @@
+// Package tlsreload serves a TLS certificate that can be replaced on disk without restarting,
+// e.g. when a certificate manager renews it.
+package tlsreload
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkInterval is how often handshakes look at the files for changes.
+const checkInterval = time.Minute
+
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// New loads the key pair once so a broken certificate fails at startup.
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the key pair from disk. The previous certificate stays in use when it fails.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tlsreload: load %s: %w", r.certFile, err)
+	}
+	modTime := r.latestModTime()
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate. At most once per checkInterval it
+// reloads the pair when either file changed since it was last read.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, modTime, due := r.cert, r.modTime, time.Since(r.checkedAt) >= checkInterval
+	r.mu.RUnlock()
+
+	if due {
+		r.mu.Lock()
+		r.checkedAt = time.Now()
+		r.mu.Unlock()
+
+		if r.latestModTime().After(modTime) {
+			// A half-written renewal fails here and is retried on a later handshake
+			if err := r.Reload(); err == nil {
+				r.mu.RLock()
+				cert = r.cert
+				r.mu.RUnlock()
+			}
+		}
+	}
+	return cert, nil
+}
+
+func (r *Reloader) latestModTime() time.Time {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
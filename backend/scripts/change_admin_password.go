@@ -4,6 +4,7 @@
 package main
 
 import (
+	"arshaka-backend/internal/config"
 	"arshaka-backend/pkg/database"
 	"bufio"
 	"fmt"
@@ -21,9 +22,13 @@ func main() {
 	fmt.Println("=== CHANGE ADMIN PASSWORD ===")
 	fmt.Println()
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Database connection
-	dbConfig := database.GetConfigFromEnv()
-	db, err := database.NewMySQLConnection(dbConfig)
+	db, err := database.NewMySQLConnection(cfg.Database.Connection())
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -76,7 +81,7 @@ func main() {
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), cfg.Auth.BcryptCost)
 	if err != nil {
 		log.Fatal("Error hashing password:", err)
 	}
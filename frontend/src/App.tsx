@@ -11,6 +11,7 @@ import AdminBannerPage from './pages/admin/AdminBannerPage';
 import AdminKegiatanPage from './pages/admin/AdminKegiatanPage';
 import AdminStrukturPage from './pages/admin/AdminStrukturPage';
 import AdminQRCodePage from './pages/admin/AdminQRCodePage';
+import AdminTrashPage from './pages/admin/AdminTrashPage';
 
 function App() {
   return (
@@ -28,6 +29,7 @@ function App() {
           <Route path="/admin/kegiatan" element={<AdminKegiatanPage />} />
           <Route path="/admin/struktur" element={<AdminStrukturPage />} />
           <Route path="/admin/qrcode" element={<AdminQRCodePage />} />
+          <Route path="/admin/trash" element={<AdminTrashPage />} />
         </Routes>
       </div>
     </Router>
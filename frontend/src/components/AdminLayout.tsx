@@ -6,6 +6,7 @@ import {
   CalendarDaysIcon,
   UserGroupIcon,
   QrCodeIcon,
+  TrashIcon,
   ArrowRightOnRectangleIcon,
   EyeIcon,
   Bars3Icon,
@@ -33,6 +34,7 @@ const AdminLayout: React.FC<AdminLayoutProps> = ({ children }) => {
     { path: '/admin/kegiatan', label: 'Kegiatan', icon: CalendarDaysIcon, description: 'Activities & Events' },
     { path: '/admin/struktur', label: 'Struktur', icon: UserGroupIcon, description: 'Organization Members' },
     { path: '/admin/qrcode', label: 'QR Code', icon: QrCodeIcon, description: 'QR Code Management' },
+    { path: '/admin/trash', label: 'Trash', icon: TrashIcon, description: 'Restore Deleted Items' },
   ];
 
   const isActive = (path: string) => {
@@ -297,7 +297,7 @@ const HomePage: React.FC = () => {
                 {kegiatan.map((item) => (
                   <SwiperSlide key={item.id}>
                     <Link
-                      to={`/kegiatan/${item.id}`}
+                      to={`/kegiatan/${item.slug || item.id}`}
                       className="block group"
                     >
                       <div className="card bg-white rounded-xl shadow-maroon-sm border border-maroon-100 overflow-hidden hover:shadow-maroon-lg transition-all duration-300 group-hover:-translate-y-2 hover:border-maroon-300 h-full flex flex-col mx-2">
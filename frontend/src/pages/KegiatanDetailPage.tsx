@@ -40,13 +40,16 @@ const KegiatanDetailPage: React.FC = () => {
 
   useEffect(() => {
     if (id) {
-      fetchKegiatan(parseInt(id));
+      fetchKegiatan(id);
     }
   }, [id]);
 
-  const fetchKegiatan = async (kegiatanId: number) => {
+  // Links may carry either the slug or, for older shares, the numeric ID
+  const fetchKegiatan = async (idOrSlug: string) => {
     try {
-      const data = await kegiatanAPI.getById(kegiatanId);
+      const data = /^\d+$/.test(idOrSlug)
+        ? await kegiatanAPI.getById(parseInt(idOrSlug))
+        : await kegiatanAPI.getBySlug(idOrSlug);
       setKegiatan(data);
     } catch (error) {
       console.error('Error fetching kegiatan:', error);
@@ -194,11 +197,11 @@ const KegiatanDetailPage: React.FC = () => {
             </h1>
 
             {/* Description */}
-            <div className="prose prose-lg max-w-none">
-              <div className="text-gray-700 leading-relaxed whitespace-pre-line">
-                {kegiatan.deskripsi}
-              </div>
-            </div>
+            {/* deskripsi_html is sanitized server-side, so it is safe to inject */}
+            <div
+              className="prose prose-lg max-w-none text-gray-700 leading-relaxed"
+              dangerouslySetInnerHTML={{ __html: kegiatan.deskripsi_html }}
+            />
 
             {/* Photo Count */}
             {photos && photos.length > 0 && (
@@ -4,9 +4,17 @@ import Modal from '../../components/Modal';
 import PhotoManager from '../../components/PhotoManager';
 import OptimizedImage from '../../components/OptimizedImage';
 import { useToast } from '../../components/Toast';
-import { kegiatanAPI, kegiatanPhotosAPI, uploadAPI, Kegiatan } from '../../services/api';
+import { kegiatanAPI, kegiatanPhotosAPI, uploadAPI, Kegiatan, KegiatanStatus } from '../../services/api';
 // Icons will be imported as needed
 
+// toLocalInputValue formats an ISO timestamp as local time for a datetime-local input, which
+// new Date(value) reads back as local time when saving.
+const toLocalInputValue = (iso: string): string => {
+  const date = new Date(iso);
+  const pad = (n: number) => String(n).padStart(2, '0');
+  return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}T${pad(date.getHours())}:${pad(date.getMinutes())}`;
+};
+
 const AdminKegiatanPage: React.FC = () => {
   const [kegiatan, setKegiatan] = useState<Kegiatan[]>([]);
   const [loading, setLoading] = useState(true);
@@ -17,6 +25,8 @@ const AdminKegiatanPage: React.FC = () => {
     deskripsi: '',
     cover: '',
     tanggal: '',
+    status: 'draft' as KegiatanStatus,
+    publish_at: '',
   });
   const [photos, setPhotos] = useState<Array<{
     id?: number;
@@ -36,7 +46,7 @@ const AdminKegiatanPage: React.FC = () => {
   const fetchKegiatan = useCallback(async (showLoading = true) => {
     if (showLoading) setLoading(true);
     try {
-      const data = await kegiatanAPI.getAll();
+      const data = await kegiatanAPI.getAllAdmin();
       setKegiatan(data);
     } catch (err) {
       error('Failed to fetch kegiatan');
@@ -57,12 +67,14 @@ const AdminKegiatanPage: React.FC = () => {
         deskripsi: item.deskripsi,
         cover: item.cover,
         tanggal: item.tanggal.split('T')[0], // Format for date input
+        status: item.status,
+        publish_at: item.publish_at ? toLocalInputValue(item.publish_at) : '', // Format for datetime-local input
       });
 
       // Load existing photos from API (fresh data)
       console.log('Loading existing photos for kegiatan:', item.id); // Debug log
       try {
-        const freshKegiatanData = await kegiatanAPI.getById(item.id);
+        const freshKegiatanData = await kegiatanAPI.getByIdAdmin(item.id);
         console.log('Fresh kegiatan data:', freshKegiatanData); // Debug log
 
         const existingPhotos = freshKegiatanData.fotos?.map((foto, index) => ({
@@ -92,6 +104,8 @@ const AdminKegiatanPage: React.FC = () => {
         deskripsi: '',
         cover: '',
         tanggal: '',
+        status: 'draft',
+        publish_at: '',
       });
       setPhotos([]);
     }
@@ -106,6 +120,8 @@ const AdminKegiatanPage: React.FC = () => {
       deskripsi: '',
       cover: '',
       tanggal: '',
+      status: 'draft',
+      publish_at: '',
     });
     setPhotos([]);
   };
@@ -114,7 +130,7 @@ const AdminKegiatanPage: React.FC = () => {
     if (editingKegiatan) {
       try {
         console.log('Refreshing photos for kegiatan:', editingKegiatan.id);
-        const freshData = await kegiatanAPI.getById(editingKegiatan.id);
+        const freshData = await kegiatanAPI.getByIdAdmin(editingKegiatan.id);
         console.log('Fresh data received:', freshData);
 
         const refreshedPhotos = freshData.fotos?.map((foto, index) => ({
@@ -134,7 +150,7 @@ const AdminKegiatanPage: React.FC = () => {
     }
   };
 
-  const handleInputChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) => {
+  const handleInputChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement | HTMLSelectElement>) => {
     const { name, value } = e.target;
     setFormData(prev => ({
       ...prev,
@@ -173,12 +189,13 @@ const AdminKegiatanPage: React.FC = () => {
       const submitData = {
         ...formData,
         tanggal: new Date(formData.tanggal).toISOString(),
+        publish_at: formData.publish_at ? new Date(formData.publish_at).toISOString() : null,
       };
 
       let kegiatanId: number;
 
       if (editingKegiatan) {
-        await kegiatanAPI.update(editingKegiatan.id, submitData);
+        await kegiatanAPI.update(editingKegiatan.id, submitData, editingKegiatan.version);
         kegiatanId = editingKegiatan.id;
 
         // Handle photo updates for existing kegiatan
@@ -232,7 +249,13 @@ const AdminKegiatanPage: React.FC = () => {
       handleCloseModal();
       // Refresh kegiatan list to get updated data with photos
       refreshKegiatan();
-    } catch (err) {
+    } catch (err: any) {
+      if (err?.response?.status === 412 && editingKegiatan) {
+        // Someone else saved first; keep the form open on top of their version
+        setEditingKegiatan(err.response.data.data);
+        error('This kegiatan was changed by another admin. Review and save again to overwrite their changes.');
+        return;
+      }
       console.error('Submit error:', err);
       error('Failed to save kegiatan');
     }
@@ -459,6 +482,38 @@ const AdminKegiatanPage: React.FC = () => {
             />
           </div>
 
+          <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+            <div>
+              <label htmlFor="kegiatan-status" className="block text-sm font-medium text-gray-700 mb-2">
+                Status
+              </label>
+              <select
+                id="kegiatan-status"
+                name="status"
+                value={formData.status}
+                onChange={handleInputChange}
+                className="w-full px-3 py-2 border border-gray-300 rounded-lg focus:outline-none focus:ring-2 focus:ring-maroon-700 focus:border-maroon-700 transition-colors"
+              >
+                <option value="draft">Draft</option>
+                <option value="published">Published</option>
+                <option value="archived">Archived</option>
+              </select>
+            </div>
+            <div>
+              <label htmlFor="kegiatan-publish-at" className="block text-sm font-medium text-gray-700 mb-2">
+                Jadwal Publish
+              </label>
+              <input
+                id="kegiatan-publish-at"
+                type="datetime-local"
+                name="publish_at"
+                value={formData.publish_at}
+                onChange={handleInputChange}
+                className="w-full px-3 py-2 border border-gray-300 rounded-lg focus:outline-none focus:ring-2 focus:ring-maroon-700 focus:border-maroon-700 transition-colors"
+              />
+            </div>
+          </div>
+
           <div>
             <label htmlFor="kegiatan-cover" className="block text-sm font-medium text-gray-700 mb-2">
               Cover Image
@@ -494,10 +549,14 @@ const AdminKegiatanPage: React.FC = () => {
               name="deskripsi"
               value={formData.deskripsi}
               onChange={handleInputChange}
-              rows={4}
+              rows={8}
               className="w-full px-3 py-2 border border-gray-300 rounded-lg focus:outline-none focus:ring-2 focus:ring-maroon-700 focus:border-maroon-700 transition-colors"
               required
             />
+            <p className="mt-1 text-xs text-gray-500">
+              Mendukung Markdown: **tebal**, *miring*, # judul, - daftar, [tautan](https://...) dan
+              gambar ![keterangan](/uploads/nama-file.jpg) dari file yang sudah diupload.
+            </p>
           </div>
 
           {/* Photo Gallery Manager */}
@@ -3,7 +3,7 @@ import AdminLayout from '../../components/AdminLayout';
 import Modal from '../../components/Modal';
 import OptimizedImage from '../../components/OptimizedImage';
 import { useToast } from '../../components/Toast';
-import { strukturAPI, pembinaAPI, uploadAPI, Struktur, Pembina } from '../../services/api';
+import { strukturAPI, pembinaAPI, uploadAPI, Struktur, Pembina, getApiError } from '../../services/api';
 
 const AdminStrukturPage: React.FC = () => {
   const [activeTab, setActiveTab] = useState<'struktur' | 'pembina'>('struktur');
@@ -216,8 +216,9 @@ const AdminStrukturPage: React.FC = () => {
       fetchPembina();
     } catch (err: any) {
       console.error('handlePembinaSubmit error:', err);
-      if (err.response?.status === 400 && err.response?.data?.includes('maksimal')) {
-        error('Maksimal 2 pembina sudah tercapai!');
+      const apiError = getApiError(err);
+      if (apiError?.code === 'pembina_limit_reached') {
+        error(apiError.message);
       } else {
         error('Failed to save pembina');
       }
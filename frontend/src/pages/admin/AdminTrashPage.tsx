@@ -0,0 +1,153 @@
+import React, { useState, useEffect, useCallback } from 'react';
+import AdminLayout from '../../components/AdminLayout';
+import OptimizedImage from '../../components/OptimizedImage';
+import { useToast } from '../../components/Toast';
+import { trashAPI, TrashItem, TrashItemType, getApiError } from '../../services/api';
+import {
+  ArrowUturnLeftIcon,
+  TrashIcon,
+} from '@heroicons/react/24/outline';
+
+const typeLabels: Record<TrashItemType, string> = {
+  kegiatan: 'Kegiatan',
+  kegiatan_photo: 'Foto Kegiatan',
+  banner: 'Banner',
+  struktur: 'Struktur',
+  pembina: 'Pembina',
+  qrcode: 'QR Code',
+};
+
+const formatDate = (value: string) =>
+  new Date(value).toLocaleDateString('id-ID', {
+    day: 'numeric',
+    month: 'short',
+    year: 'numeric',
+  });
+
+const AdminTrashPage: React.FC = () => {
+  const [items, setItems] = useState<TrashItem[]>([]);
+  const [loading, setLoading] = useState(true);
+  const { ToastContainer, success, error } = useToast();
+
+  useEffect(() => {
+    fetchItems();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
+
+  const fetchItems = useCallback(async () => {
+    try {
+      const data = await trashAPI.getAll();
+      setItems(data);
+    } catch (err) {
+      error('Failed to fetch trash');
+    } finally {
+      setLoading(false);
+    }
+  }, [error]);
+
+  const handleRestore = async (item: TrashItem) => {
+    try {
+      await trashAPI.restore(item.type, item.id);
+      success(`${typeLabels[item.type]} restored successfully`);
+      fetchItems();
+    } catch (err: any) {
+      error(getApiError(err)?.message || 'Failed to restore item');
+    }
+  };
+
+  const handlePurge = async (item: TrashItem) => {
+    if (!window.confirm(`Permanently delete "${item.title}"? This cannot be undone.`)) {
+      return;
+    }
+
+    try {
+      await trashAPI.purge(item.type, item.id);
+      success('Item permanently deleted');
+      fetchItems();
+    } catch (err) {
+      error('Failed to delete item');
+    }
+  };
+
+  if (loading) {
+    return (
+      <AdminLayout>
+        <div className="flex items-center justify-center h-64">
+          <div className="text-xl text-gray-600">Loading...</div>
+        </div>
+      </AdminLayout>
+    );
+  }
+
+  return (
+    <AdminLayout>
+      <div className="space-y-8">
+        {/* Header */}
+        <div>
+          <h1 className="text-3xl font-bold text-gray-900">Trash</h1>
+          <p className="text-gray-600 mt-1">
+            Deleted items are kept here until they are permanently removed on their purge date
+          </p>
+        </div>
+
+        {items.length === 0 ? (
+          <div className="bg-white rounded-xl border-2 border-dashed border-gray-300 p-12 text-center">
+            <TrashIcon className="mx-auto h-12 w-12 text-gray-400 mb-4" />
+            <h3 className="text-lg font-medium text-gray-900 mb-2">Trash is empty</h3>
+            <p className="text-gray-500">Deleted items will show up here</p>
+          </div>
+        ) : (
+          <div className="bg-white rounded-xl shadow-maroon-sm border border-maroon-100 divide-y divide-gray-100">
+            {items.map((item) => (
+              <div key={`${item.type}-${item.id}`} className="flex items-center gap-4 p-4">
+                <div className="w-20 h-14 flex-shrink-0 rounded-lg overflow-hidden bg-gray-100">
+                  {item.image_url && (
+                    <OptimizedImage
+                      src={item.image_url}
+                      alt={item.title}
+                      className="w-full h-full"
+                      loading="lazy"
+                    />
+                  )}
+                </div>
+
+                <div className="flex-1 min-w-0">
+                  <div className="flex items-center gap-2">
+                    <span className="text-xs font-medium text-maroon-700 bg-maroon-50 px-2 py-0.5 rounded-full">
+                      {typeLabels[item.type]}
+                    </span>
+                    <p className="text-sm font-medium text-gray-900 truncate">{item.title}</p>
+                  </div>
+                  <p className="text-xs text-gray-500 mt-1">
+                    Deleted {formatDate(item.deleted_at)} · Permanently removed {formatDate(item.purge_at)}
+                  </p>
+                </div>
+
+                <div className="flex space-x-2">
+                  <button
+                    onClick={() => handleRestore(item)}
+                    className="inline-flex items-center px-3 py-2 bg-gradient-to-r from-blue-50 to-blue-100 text-blue-700 text-sm font-medium rounded-lg hover:from-blue-100 hover:to-blue-200 hover:text-blue-800 transition-all duration-200 shadow-sm hover:shadow-md"
+                  >
+                    <ArrowUturnLeftIcon className="h-4 w-4 mr-2" />
+                    Restore
+                  </button>
+                  <button
+                    onClick={() => handlePurge(item)}
+                    className="inline-flex items-center px-3 py-2 bg-gradient-to-r from-red-50 to-red-100 text-red-700 text-sm font-medium rounded-lg hover:from-red-100 hover:to-red-200 hover:text-red-800 transition-all duration-200 shadow-sm hover:shadow-md"
+                  >
+                    <TrashIcon className="h-4 w-4 mr-2" />
+                    Delete Forever
+                  </button>
+                </div>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+
+      <ToastContainer />
+    </AdminLayout>
+  );
+};
+
+export default AdminTrashPage;
@@ -11,14 +11,69 @@ export interface Banner {
 export interface Kegiatan {
   id: number;
   judul: string;
+  slug: string;
   deskripsi: string;
+  // Sanitized HTML rendered by the backend from the Markdown in deskripsi
+  deskripsi_html: string;
   cover: string;
   tanggal: string;
+  start_at: string;
+  end_at: string;
+  timezone: string;
+  state: 'upcoming' | 'ongoing' | 'past';
+  location: KegiatanLocation;
+  status: KegiatanStatus;
+  publish_at?: string | null;
+  // Bumped on every save; sent back as If-Match so concurrent edits are detected
+  version: number;
   created_at: string;
   updated_at: string;
   fotos?: KegiatanFoto[];
+  categories?: Category[];
+  tags?: Tag[];
 }
 
+export interface Category {
+  id: number;
+  name: string;
+  slug: string;
+  description: string;
+  kegiatan_count: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Tag {
+  id: number;
+  name: string;
+  slug: string;
+  created_at: string;
+}
+
+export type TrashItemType = 'kegiatan' | 'kegiatan_photo' | 'banner' | 'struktur' | 'pembina' | 'qrcode';
+
+export interface TrashItem {
+  type: TrashItemType;
+  id: number;
+  title: string;
+  image_url?: string;
+  deleted_at: string;
+  purge_at: string;
+}
+
+export interface KegiatanLocation {
+  name: string;
+  address: string;
+  latitude: number | null;
+  longitude: number | null;
+}
+
+export type KegiatanStatus = 'draft' | 'published' | 'archived';
+
+// Fields accepted when creating or updating a kegiatan; the server derives the rest
+export type KegiatanInput = Pick<Kegiatan, 'judul' | 'deskripsi' | 'cover' | 'tanggal'> &
+  Partial<Pick<Kegiatan, 'status' | 'publish_at' | 'start_at' | 'end_at' | 'timezone' | 'location' | 'categories' | 'tags'>>;
+
 export interface KegiatanFoto {
   id: number;
   kegiatan_id: number;
@@ -81,6 +136,15 @@ export interface ApiResponse<T> {
   message?: string;
 }
 
+// Body of every failed request: { success: false, error: ApiError }
+export interface ApiError {
+  code: string;
+  message: string;
+  fields?: Record<string, string>;
+}
+
+export const getApiError = (err: any): ApiError | undefined => err?.response?.data?.error;
+
 // API Base URL
 const API_BASE_URL = process.env.REACT_APP_API_URL || 'http://localhost:8080';
 
@@ -165,20 +229,37 @@ export const bannerAPI = {
 
 // Kegiatan API
 export const kegiatanAPI = {
-  getAll: async (): Promise<Kegiatan[]> => {
-    const response = await api.get<ApiResponse<Kegiatan[]>>('/kegiatan');
+  getAll: async (filter?: { category?: string; tag?: string }): Promise<Kegiatan[]> => {
+    const response = await api.get<ApiResponse<Kegiatan[]>>('/kegiatan', { params: filter });
+    return response.data.data;
+  },
+  getUpcoming: async (limit?: number): Promise<Kegiatan[]> => {
+    const response = await api.get<ApiResponse<Kegiatan[]>>('/kegiatan/upcoming', { params: { limit } });
     return response.data.data;
   },
   getById: async (id: number): Promise<Kegiatan> => {
     const response = await api.get<ApiResponse<Kegiatan>>(`/kegiatan/${id}`);
     return response.data.data;
   },
-  create: async (kegiatan: Omit<Kegiatan, 'id' | 'created_at' | 'updated_at' | 'fotos'>): Promise<Kegiatan> => {
+  getBySlug: async (slug: string): Promise<Kegiatan> => {
+    const response = await api.get<ApiResponse<Kegiatan>>(`/kegiatan/slug/${encodeURIComponent(slug)}`);
+    return response.data.data;
+  },
+  getAllAdmin: async (): Promise<Kegiatan[]> => {
+    const response = await api.get<ApiResponse<Kegiatan[]>>('/admin/kegiatan');
+    return response.data.data;
+  },
+  getByIdAdmin: async (id: number): Promise<Kegiatan> => {
+    const response = await api.get<ApiResponse<Kegiatan>>(`/admin/kegiatan/${id}`);
+    return response.data.data;
+  },
+  create: async (kegiatan: KegiatanInput): Promise<Kegiatan> => {
     const response = await api.post<ApiResponse<Kegiatan>>('/admin/kegiatan', kegiatan);
     return response.data.data;
   },
-  update: async (id: number, kegiatan: Omit<Kegiatan, 'id' | 'created_at' | 'updated_at' | 'fotos'>): Promise<Kegiatan> => {
-    const response = await api.put<ApiResponse<Kegiatan>>(`/admin/kegiatan/${id}`, kegiatan);
+  update: async (id: number, kegiatan: KegiatanInput, version?: number): Promise<Kegiatan> => {
+    const headers = version ? { 'If-Match': `"${version}"` } : undefined;
+    const response = await api.put<ApiResponse<Kegiatan>>(`/admin/kegiatan/${id}`, kegiatan, { headers });
     return response.data.data;
   },
   delete: async (id: number): Promise<void> => {
@@ -189,7 +270,7 @@ export const kegiatanAPI = {
 // Struktur API
 export const strukturAPI = {
   getAll: async (): Promise<Struktur[]> => {
-    const response = await api.get<ApiResponse<Struktur[]>>(`/struktur?t=${Date.now()}`);
+    const response = await api.get<ApiResponse<Struktur[]>>('/struktur');
     return response.data.data;
   },
   getById: async (id: number): Promise<Struktur> => {
@@ -214,7 +295,7 @@ export const strukturAPI = {
 // Pembina API
 export const pembinaAPI = {
   getAll: async (): Promise<Pembina[]> => {
-    const response = await api.get<ApiResponse<Pembina[]>>(`/pembina?t=${Date.now()}`);
+    const response = await api.get<ApiResponse<Pembina[]>>('/pembina');
     return response.data.data;
   },
   getById: async (id: number): Promise<Pembina> => {
@@ -266,6 +347,47 @@ export const qrcodeAPI = {
   },
 };
 
+// Category API
+export const categoryAPI = {
+  getAll: async (): Promise<Category[]> => {
+    const response = await api.get<ApiResponse<Category[]>>('/categories');
+    return response.data.data;
+  },
+  create: async (category: Pick<Category, 'name' | 'slug' | 'description'>): Promise<Category> => {
+    const response = await api.post<ApiResponse<Category>>('/admin/categories', category);
+    return response.data.data;
+  },
+  update: async (id: number, category: Pick<Category, 'name' | 'slug' | 'description'>): Promise<Category> => {
+    const response = await api.put<ApiResponse<Category>>(`/admin/categories/${id}`, category);
+    return response.data.data;
+  },
+  delete: async (id: number): Promise<void> => {
+    await api.delete(`/admin/categories/${id}`);
+  },
+};
+
+// Tag API
+export const tagAPI = {
+  getAll: async (): Promise<Tag[]> => {
+    const response = await api.get<ApiResponse<Tag[]>>('/tags');
+    return response.data.data;
+  },
+};
+
+// Trash API
+export const trashAPI = {
+  getAll: async (): Promise<TrashItem[]> => {
+    const response = await api.get<ApiResponse<TrashItem[]>>('/admin/trash');
+    return response.data.data;
+  },
+  restore: async (type: TrashItemType, id: number): Promise<void> => {
+    await api.post(`/admin/trash/${type}/${id}/restore`);
+  },
+  purge: async (type: TrashItemType, id: number): Promise<void> => {
+    await api.delete(`/admin/trash/${type}/${id}`);
+  },
+};
+
 // Kegiatan Photos API
 export const kegiatanPhotosAPI = {
   getByKegiatanId: async (kegiatanId: number): Promise<KegiatanFoto[]> => {